@@ -0,0 +1,253 @@
+package polygol
+
+import "math"
+
+// With Polygol.Antimeridian set, coordinates are longitude and latitude in
+// degrees, and a ring crosses the antimeridian wherever two consecutive
+// positions are more than 180° of longitude apart: the edge between them
+// takes the short way around, through ±180°, rather than across the whole
+// map.
+//
+// Before the sweep, every ring of a geometry that crosses the antimeridian or
+// strays out of [-180, 180] is unwrapped into continuous longitudes, cut into
+// 360° wide windows and every piece shifted back into [-180, 180]. The pieces
+// of the rings are then put back together into polygons, subtracting the
+// holes from their own shell under NonZero and combining all rings with XOR
+// under EvenOdd, so that pieces of the same geometry never overlap. As all of
+// the inputs are then within [-180, 180], so are the outputs, which come out
+// cut at the antimeridian as RFC 7946 recommends.
+//
+// Inputs all within [0, 360], with longitudes past 180, get their outputs
+// back in [0, 360] instead: the outputs are cut at the prime meridian, the
+// pieces west of it shifted east by 360° and joined again with those they
+// were cut from at the antimeridian.
+//
+// A ring that winds all the way around the globe encloses a pole. Which pole
+// follows the RFC 7946 orientation, the area of the polygon being on the
+// left: an exterior ring going east encloses the north pole and one going
+// west the south pole, and the other way around for interior rings. The ring
+// is closed along the pole before it is cut, so that a polar cap comes out as
+// the area between the ring and latitude ±90°.
+
+// runAntimeridian runs a Boolean operation on the inputs split at the
+// antimeridian, returning the result in the longitudes of the inputs.
+func (p *Polygol) runAntimeridian(opType string, geom Geom, moreGeoms []Geom) (Geom, error) {
+	inputs := append([]Geom{geom}, moreGeoms...)
+	split := make([]Geom, len(inputs))
+	for i := range inputs {
+		var err error
+		if split[i], err = p.splitAntimeridian(inputs[i]); err != nil {
+			return nil, err
+		}
+	}
+	result, err := p.newOperation(opType).run(split[0], split[1:]...)
+	if err != nil || !isEastLongitudes(inputs) {
+		return result, err
+	}
+
+	// cut at the prime meridian, and the western half shifted east
+	minLon, minLat := math.Inf(1), math.Inf(1)
+	maxLat := math.Inf(-1)
+	for _, poly := range result {
+		for _, ring := range poly {
+			for _, pt := range ring {
+				minLon = math.Min(minLon, pt[0])
+				minLat, maxLat = math.Min(minLat, pt[1]), math.Max(maxLat, pt[1])
+			}
+		}
+	}
+	if minLon >= 0 {
+		return result, nil
+	}
+	west, err := p.newOperation("intersection").run(result, Geom{{{{-180, minLat}, {0, minLat}, {0, maxLat}, {-180, maxLat}, {-180, minLat}}}})
+	if err != nil {
+		return nil, err
+	}
+	east, err := p.newOperation("intersection").run(result, Geom{{{{0, minLat}, {180, minLat}, {180, maxLat}, {0, maxLat}, {0, minLat}}}})
+	if err != nil {
+		return nil, err
+	}
+	for _, poly := range west {
+		for _, ring := range poly {
+			for _, pt := range ring {
+				pt[0] += 360
+			}
+		}
+	}
+	// joining the pieces cut at 180 again
+	return p.newOperation("union").run(east, west)
+}
+
+// isEastLongitudes tells whether the longitudes of the inputs are all within
+// [0, 360], some of them past 180.
+func isEastLongitudes(inputs []Geom) bool {
+	east := false
+	for _, geom := range inputs {
+		for _, poly := range geom {
+			for _, ring := range poly {
+				for _, pos := range ring {
+					if len(pos) < 2 {
+						continue
+					}
+					if pos[0] < 0 || pos[0] > 360 {
+						return false
+					}
+					east = east || pos[0] > 180
+				}
+			}
+		}
+	}
+	return east
+}
+
+// splitAntimeridian returns a geometry unchanged unless it crosses the
+// antimeridian, else the geometry cut and shifted into [-180, 180].
+func (p *Polygol) splitAntimeridian(geom Geom) (Geom, error) {
+	if !crossesAntimeridian(geom) {
+		return geom, nil
+	}
+
+	parts := []Geom{}
+	for _, poly := range geom {
+		rings := []Geom{}
+		for i, ring := range poly {
+			pieces, err := p.splitRing(ring, i == 0)
+			if err != nil {
+				return nil, err
+			}
+			rings = append(rings, pieces)
+		}
+		if len(rings) == 0 {
+			continue
+		}
+		if p.FillRule == EvenOdd {
+			parts = append(parts, rings...)
+			continue
+		}
+		// NonZero: a hole only removes area from its own shell
+		polygon, err := p.newOperation("difference").run(rings[0], rings[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, polygon)
+	}
+
+	if len(parts) == 0 {
+		return Geom{}, nil
+	}
+	if p.FillRule == EvenOdd {
+		return p.newOperation("xor").run(parts[0], parts[1:]...)
+	}
+	split := Geom{}
+	for _, part := range parts {
+		split = append(split, part...)
+	}
+	return split, nil
+}
+
+// crossesAntimeridian tells whether any ring of a geometry has an edge
+// longer than 180° of longitude or a position out of [-180, 180].
+func crossesAntimeridian(geom Geom) bool {
+	for _, poly := range geom {
+		for _, ring := range poly {
+			for i, pos := range ring {
+				if len(pos) < 2 {
+					continue
+				}
+				if pos[0] < -180 || pos[0] > 180 {
+					return true
+				}
+				prev := ring[(i+len(ring)-1)%len(ring)]
+				if len(prev) >= 2 && math.Abs(pos[0]-prev[0]) > 180 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// splitRing returns the area enclosed by a ring as polygons within
+// [-180, 180].
+func (p *Polygol) splitRing(ring [][]float64, exterior bool) (Geom, error) {
+	pts, winding := unwrapRing(ring)
+	if len(pts) < 3 {
+		return Geom{}, nil
+	}
+	if winding != 0 {
+		pole := -90.0
+		if (winding > 0) == exterior {
+			pole = 90
+		}
+		start := pts[0][0]
+		end := start + 360*float64(winding)
+		pts = append(pts, []float64{end, pts[0][1]}, []float64{end, pole}, []float64{start, pole})
+	}
+
+	minLon, minLat := math.Inf(1), math.Inf(1)
+	maxLon, maxLat := math.Inf(-1), math.Inf(-1)
+	for _, pt := range pts {
+		minLon, maxLon = math.Min(minLon, pt[0]), math.Max(maxLon, pt[0])
+		minLat, maxLat = math.Min(minLat, pt[1]), math.Max(maxLat, pt[1])
+	}
+	first := math.Floor((minLon + 180) / 360)
+	last := math.Ceil((maxLon - 180) / 360)
+	if first == 0 && last == 0 {
+		return Geom{{pts}}, nil
+	}
+
+	pieces := Geom{}
+	for k := first; k <= last; k++ {
+		west, east := 360*k-180, 360*k+180
+		window := Geom{{{{west, minLat}, {east, minLat}, {east, maxLat}, {west, maxLat}, {west, minLat}}}}
+		clipped, err := p.newOperation("intersection").run(Geom{{pts}}, window)
+		if err != nil {
+			return nil, err
+		}
+		for _, poly := range clipped {
+			for _, r := range poly {
+				for _, pt := range r {
+					pt[0] -= 360 * k
+				}
+			}
+		}
+		pieces = append(pieces, clipped...)
+	}
+	return pieces, nil
+}
+
+// unwrapRing makes the longitudes of a ring continuous, every edge taking
+// the short way around the globe, and returns how many times the ring winds
+// around it: positive going east and negative going west. A ring winding
+// around the globe loses its closing position, which is off by 360° from
+// the first one.
+func unwrapRing(ring [][]float64) ([][]float64, int) {
+	pts := make([][]float64, 0, len(ring))
+	for _, pos := range ring {
+		if len(pos) < 2 {
+			continue
+		}
+		if len(pts) == 0 {
+			pts = append(pts, []float64{pos[0], pos[1]})
+			continue
+		}
+		prev := pts[len(pts)-1]
+		pts = append(pts, []float64{prev[0] + wrapLongitude(pos[0]-prev[0]), pos[1]})
+	}
+	if len(pts) == 0 {
+		return pts, 0
+	}
+
+	first, last := pts[0], pts[len(pts)-1]
+	end := last[0] + wrapLongitude(first[0]-last[0])
+	winding := int(math.Round((end - first[0]) / 360))
+	if winding != 0 && len(pts) > 1 && last[0] == first[0]+360*float64(winding) && last[1] == first[1] {
+		pts = pts[:len(pts)-1]
+	}
+	return pts, winding
+}
+
+// wrapLongitude brings a difference of longitudes into [-180, 180].
+func wrapLongitude(d float64) float64 {
+	return d - 360*math.Round(d/360)
+}
@@ -0,0 +1,173 @@
+package polygol
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAntimeridian(t *testing.T) {
+	p := &Polygol{Antimeridian: true}
+
+	fiji := Geom{{{{178, -17}, {-179, -17}, {-179, -16}, {178, -16}, {178, -17}}}}
+	shifted := Geom{{{{179.5, -18}, {180.5, -18}, {180.5, -15}, {179.5, -15}, {179.5, -18}}}}
+	withHole := Geom{{
+		{{170, -10}, {-170, -10}, {-170, 10}, {170, 10}, {170, -10}},
+		{{-175, -5}, {175, -5}, {175, 5}, {-175, 5}, {-175, -5}},
+	}}
+	// counter-clockwise polar rings: west around the south pole, east around
+	// the north one
+	antarctica := Geom{{{{0, -80}, {-90, -80}, {180, -80}, {90, -80}, {0, -80}}}}
+	arctic := Geom{{{{0, 80}, {90, 80}, {180, 80}, {-90, 80}, {0, 80}}}}
+
+	testCases := []struct {
+		name  string
+		op    func(geom Geom, moreGeoms ...Geom) (Geom, error)
+		geoms []Geom
+		area  float64
+		polys int
+	}{
+		{"union of one crossing", p.Union, []Geom{fiji}, 3, 2},
+		{"intersection of crossings", p.Intersection, []Geom{fiji, shifted}, 1, 2},
+		{"union beyond 180", p.Union, []Geom{fiji, shifted}, 5, 2},
+		{"difference with a hole", p.Difference, []Geom{withHole, fiji}, 400 - 100, 2},
+		{"south polar cap", p.Union, []Geom{antarctica}, 360 * 10, 1},
+		{"north polar cap", p.Union, []Geom{arctic}, 360 * 10, 1},
+		{"polar caps apart", p.Intersection, []Geom{antarctica, arctic}, 0, 0},
+		{"polar cap and crossing", p.Intersection, []Geom{antarctica, {{{{170, -85}, {-170, -85}, {-170, -70}, {170, -70}, {170, -85}}}}}, 100, 2},
+	}
+
+	for _, tc := range testCases {
+		result, err := tc.op(tc.geoms[0], tc.geoms[1:]...)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if area := result.Area(); math.Abs(area-tc.area) > 1e-9 {
+			t.Errorf("%s: expected area %v, got %v", tc.name, tc.area, area)
+		}
+		if len(result) != tc.polys {
+			t.Errorf("%s: expected %d polygons, got %d: %v", tc.name, tc.polys, len(result), result)
+		}
+		for _, poly := range result {
+			for _, ring := range poly {
+				for _, pt := range ring {
+					if pt[0] < -180 || pt[0] > 180 {
+						t.Errorf("%s: longitude %v out of [-180, 180]", tc.name, pt[0])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAntimeridianEastLongitudes(t *testing.T) {
+	p := &Polygol{Antimeridian: true}
+
+	// inputs within [0, 360] come out within [0, 360], not cut at 180
+	shifted := Geom{{{{179.5, -18}, {180.5, -18}, {180.5, -15}, {179.5, -15}, {179.5, -18}}}}
+	result, err := p.Union(shifted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Geom{{{{179.5, -18}, {180.5, -18}, {180.5, -15}, {179.5, -15}, {179.5, -18}}}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	// cut at 360 instead, the prime meridian
+	meridian := Geom{{{{350, 0}, {10, 0}, {10, 10}, {350, 10}, {350, 0}}}}
+	result, err = p.Intersection(meridian, Geom{{{{355, 5}, {360, 5}, {360, 20}, {355, 20}, {355, 5}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = Geom{{{{355, 5}, {360, 5}, {360, 10}, {355, 10}, {355, 5}}}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+	result, err = p.Union(meridian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = Geom{
+		{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}},
+		{{{350, 0}, {360, 0}, {360, 10}, {350, 10}, {350, 0}}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	// a polar cap around the whole of [0, 360]
+	antarctica := Geom{{{{0, -80}, {270, -80}, {180, -80}, {90, -80}, {0, -80}}}}
+	result, err = p.Union(antarctica)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = Geom{{{{0, -90}, {360, -90}, {360, -80}, {0, -80}, {0, -90}}}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	// with negative longitudes among the inputs, results stay in [-180, 180]
+	fiji := Geom{{{{178, -17}, {-179, -17}, {-179, -16}, {178, -16}, {178, -17}}}}
+	result, err = p.Union(fiji, shifted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = Geom{
+		{{{-180, -18}, {-179.5, -18}, {-179.5, -17}, {-179, -17}, {-179, -16}, {-179.5, -16}, {-179.5, -15}, {-180, -15}, {-180, -18}}},
+		{{{178, -17}, {179.5, -17}, {179.5, -18}, {180, -18}, {180, -15}, {179.5, -15}, {179.5, -16}, {178, -16}, {178, -17}}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestAntimeridianPlanarUnchanged(t *testing.T) {
+	geom := Geom{{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}}}
+	expected, err := Union(geom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := (&Polygol{Antimeridian: true}).Union(geom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestAntimeridianEvenOdd(t *testing.T) {
+	p := &Polygol{Antimeridian: true, FillRule: EvenOdd}
+	// the same crossing square twice cancels out
+	fiji := [][][]float64{{{178, -17}, {-179, -17}, {-179, -16}, {178, -16}, {178, -17}}}
+	result, err := p.MakeValid(Geom{fiji, fiji})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 0 {
+		t.Errorf("expected nothing, got %v", result)
+	}
+}
+
+func TestUnwrapRing(t *testing.T) {
+	testCases := []struct {
+		ring    [][]float64
+		last    float64
+		winding int
+	}{
+		{[][]float64{{178, 0}, {-179, 0}, {-179, 1}, {178, 0}}, 178, 0},
+		{[][]float64{{0, -80}, {-90, -80}, {180, -80}, {90, -80}, {0, -80}}, 90 - 360, -1},
+		{[][]float64{{0, 80}, {90, 80}, {180, 80}, {-90, 80}}, 270, 1},
+	}
+	for i, tc := range testCases {
+		pts, winding := unwrapRing(tc.ring)
+		if winding != tc.winding {
+			t.Errorf("%d: expected winding %d, got %d", i, tc.winding, winding)
+		}
+		if last := pts[len(pts)-1][0]; last != tc.last {
+			t.Errorf("%d: expected last longitude %v, got %v", i, tc.last, last)
+		}
+	}
+}
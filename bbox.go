@@ -47,3 +47,51 @@ func (b1 Bbox) getBboxOverlap(b2 Bbox) *Bbox {
 		ur: Vector{x: upperX, y: upperY},
 	}
 }
+
+// clipRing clips a ring of points to the bbox (Sutherland-Hodgman). Points
+// where the ring crosses the bbox boundary are rounded with the given rounder.
+// As the bbox is convex, the winding number of every point inside the bbox is
+// preserved, though the clipped ring may run back and forth along the boundary.
+func (bbox Bbox) clipRing(points []*point, rounder *ptRounder) []*point {
+	points = clipRingToEdge(points, rounder,
+		func(v Vector) bool { return v.x.isGreaterThanOrEqualTo(bbox.ll.x) },
+		func(pt, v Vector) *Vector { return verticalIntersection(pt, v, bbox.ll.x) })
+	points = clipRingToEdge(points, rounder,
+		func(v Vector) bool { return v.x.isLessThanOrEqualTo(bbox.ur.x) },
+		func(pt, v Vector) *Vector { return verticalIntersection(pt, v, bbox.ur.x) })
+	points = clipRingToEdge(points, rounder,
+		func(v Vector) bool { return v.y.isGreaterThanOrEqualTo(bbox.ll.y) },
+		func(pt, v Vector) *Vector { return horizontalIntersection(pt, v, bbox.ll.y) })
+	points = clipRingToEdge(points, rounder,
+		func(v Vector) bool { return v.y.isLessThanOrEqualTo(bbox.ur.y) },
+		func(pt, v Vector) *Vector { return horizontalIntersection(pt, v, bbox.ur.y) })
+	return points
+}
+
+func clipRingToEdge(
+	points []*point,
+	rounder *ptRounder,
+	inside func(v Vector) bool,
+	intersect func(pt, v Vector) *Vector,
+) []*point {
+	clipped := []*point{}
+	for i := 0; i < len(points); i++ {
+		prevPt := points[(i+len(points)-1)%len(points)]
+		pt := points[i]
+		isInside := inside(pt.Vector)
+		if isInside != inside(prevPt.Vector) {
+			// measure from the left-most endpoint, same as when splitting segments
+			leftPt, rightPt := prevPt, pt
+			if sweepEventComparePoints(leftPt, rightPt) > 0 {
+				leftPt, rightPt = rightPt, leftPt
+			}
+			v := Vector{x: rightPt.x.minus(leftPt.x), y: rightPt.y.minus(leftPt.y)}
+			inter := intersect(leftPt.Vector, v)
+			clipped = append(clipped, rounder.round(inter.x, inter.y))
+		}
+		if isInside {
+			clipped = append(clipped, pt)
+		}
+	}
+	return clipped
+}
@@ -233,3 +233,39 @@ func TestBboxOverlap(t *testing.T) {
 	p = Bbox{ll: Vector{x: newBigNumber(5), y: newBigNumber(5)}, ur: Vector{x: newBigNumber(5), y: newBigNumber(5)}}
 	expect(t, equalBbox(*p.getBboxOverlap(p), p))
 }
+
+func TestBboxClipRing(t *testing.T) {
+	b := Bbox{ll: newVectorLit(0, 0), ur: newVectorLit(4, 4)}
+
+	equalPoints := func(pts []*point, expected [][]float64) bool {
+		if len(pts) != len(expected) {
+			return false
+		}
+		for i := range pts {
+			if !pts[i].equal(*newPoint(expected[i][0], expected[i][1])) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// ring inside the bbox is left alone
+	pts := b.clipRing([]*point{newPoint(1, 1), newPoint(3, 1), newPoint(3, 3)}, newPtRounder())
+	expect(t, equalPoints(pts, [][]float64{{1, 1}, {3, 1}, {3, 3}}))
+
+	// ring outside the bbox is clipped away
+	pts = b.clipRing([]*point{newPoint(5, 5), newPoint(7, 5), newPoint(7, 7)}, newPtRounder())
+	expect(t, len(pts) == 0)
+
+	// ring crossing the right edge of the bbox
+	pts = b.clipRing([]*point{newPoint(2, 1), newPoint(6, 1), newPoint(6, 3), newPoint(2, 3)}, newPtRounder())
+	expect(t, equalPoints(pts, [][]float64{{2, 1}, {4, 1}, {4, 3}, {2, 3}}))
+
+	// ring enclosing the bbox becomes the bbox
+	pts = b.clipRing([]*point{newPoint(-1, -1), newPoint(5, -1), newPoint(5, 5), newPoint(-1, 5)}, newPtRounder())
+	expect(t, equalPoints(pts, [][]float64{{0, 4}, {0, 0}, {4, 0}, {4, 4}}))
+
+	// diagonal edge through a corner of the bbox, which is repeated
+	pts = b.clipRing([]*point{newPoint(2, 2), newPoint(6, 2), newPoint(2, 6)}, newPtRounder())
+	expect(t, equalPoints(pts, [][]float64{{2, 4}, {2, 2}, {4, 2}, {4, 4}, {4, 4}}))
+}
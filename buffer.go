@@ -0,0 +1,205 @@
+package polygol
+
+import (
+	"fmt"
+	"math"
+)
+
+// Buffer grows a geometry by a distance, or shrinks it by a negative one.
+//
+// The geometry is first made valid, so that its exterior rings run
+// counter-clockwise and its interior rings clockwise, with the outside on
+// the right of every edge. Growing it is then the union of the geometry, a
+// rectangle on the outside of every edge and a join at every convex vertex,
+// filling the wedge between the rectangles of its two edges: a pie for round
+// joins, a triangle for bevel joins or a quadrilateral up to the meeting
+// point of the offset edges for mitre joins. Reflex vertices need no join,
+// the rectangles of their edges overlapping there.
+//
+// Shrinking a geometry is growing its complement within a box around it, and
+// taking what is left of the box, so that the reflex vertices of the
+// geometry get the joins of the convex vertices of the complement.
+
+// JoinStyle is the shape of a buffer around a convex vertex.
+type JoinStyle int
+
+const (
+	// JoinRound joins offset edges with an arc around the vertex.
+	JoinRound JoinStyle = iota
+	// JoinMitre extends offset edges until they meet, unless that's farther
+	// than MitreLimit times the distance, where it bevels instead.
+	JoinMitre
+	// JoinBevel joins offset edges with a straight line between their ends.
+	JoinBevel
+)
+
+// BufferOptions tunes Buffer. The zero value gives round joins with 8
+// segments per quarter circle.
+type BufferOptions struct {
+	Join JoinStyle
+	// QuadrantSegments is the number of segments approximating a quarter
+	// circle of a round join, 8 if not positive.
+	QuadrantSegments int
+	// MitreLimit is the longest a mitre may be, as a multiple of the
+	// distance, 5 if not positive.
+	MitreLimit float64
+}
+
+func (p *Polygol) Buffer(geom Geom, distance float64, opts BufferOptions) (Geom, error) {
+	result, err := p.buffer(geom, distance, opts)
+	if err != nil {
+		return nil, err
+	}
+	return p.output(result, []Geom{geom}), nil
+}
+
+func (p *Polygol) buffer(geom Geom, distance float64, opts BufferOptions) (Geom, error) {
+	if opts.Join < JoinRound || opts.Join > JoinBevel {
+		return nil, fmt.Errorf("unknown join style %d", opts.Join)
+	}
+	if opts.QuadrantSegments <= 0 {
+		opts.QuadrantSegments = 8
+	}
+	if opts.MitreLimit <= 0 {
+		opts.MitreLimit = 5
+	}
+	if math.IsNaN(distance) || math.IsInf(distance, 0) {
+		return nil, fmt.Errorf("invalid buffer distance %v", distance)
+	}
+
+	valid, err := p.makeValid(geom)
+	if err != nil {
+		return nil, err
+	}
+	if distance == 0 || len(valid) == 0 {
+		return valid, nil
+	}
+	if distance > 0 {
+		return grow(valid, distance, opts)
+	}
+
+	// shrink: what's left of a box once its complement grows
+	bbox, _ := geomBounds(valid)
+	margin := -2*distance + 1
+	box := Geom{{{
+		{bbox[0] - margin, bbox[1] - margin}, {bbox[2] + margin, bbox[1] - margin},
+		{bbox[2] + margin, bbox[3] + margin}, {bbox[0] - margin, bbox[3] + margin},
+		{bbox[0] - margin, bbox[1] - margin},
+	}}}
+	complement, err := newOperation("difference").run(box, valid)
+	if err != nil {
+		return nil, err
+	}
+	grown, err := grow(complement, -distance, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newOperation("difference").run(box, grown)
+}
+
+// grow buffers a valid geometry by a positive distance.
+func grow(geom Geom, distance float64, opts BufferOptions) (Geom, error) {
+	pieces := Geom{}
+	for _, poly := range geom {
+		for _, ring := range poly {
+			pieces = append(pieces, offsetPieces(ring, distance, opts)...)
+		}
+	}
+	return newOperation("union").run(geom, pieces)
+}
+
+// offsetPieces returns the rectangles on the right of the edges of a ring
+// and the joins at its left turns.
+func offsetPieces(ring [][]float64, d float64, opts BufferOptions) Geom {
+	// distinct consecutive positions, without the closing one
+	pts := [][]float64{}
+	for _, pos := range ring {
+		if len(pts) > 0 {
+			last := pts[len(pts)-1]
+			if last[0] == pos[0] && last[1] == pos[1] {
+				continue
+			}
+		}
+		pts = append(pts, pos)
+	}
+	if n := len(pts); n > 1 && pts[0][0] == pts[n-1][0] && pts[0][1] == pts[n-1][1] {
+		pts = pts[:n-1]
+	}
+	n := len(pts)
+	if n < 3 {
+		return nil
+	}
+
+	// unit normals on the right of every edge
+	normals := make([][]float64, n)
+	for i := range pts {
+		a, b := pts[i], pts[(i+1)%n]
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		l := math.Hypot(dx, dy)
+		normals[i] = []float64{dy / l, -dx / l}
+	}
+	offset := func(pos, normal []float64) []float64 {
+		return []float64{pos[0] + d*normal[0], pos[1] + d*normal[1]}
+	}
+
+	pieces := Geom{}
+	for i := range pts {
+		a, b := pts[i], pts[(i+1)%n]
+		pieces = append(pieces, [][][]float64{{a, b, offset(b, normals[i]), offset(a, normals[i]), a}})
+	}
+
+	for i := range pts {
+		// the vertex at the end of edge i, where edge j starts
+		j := (i + 1) % n
+		v := pts[j]
+		n1, n2 := normals[i], normals[j]
+		// only left turns open up a wedge on the right
+		if n1[0]*n2[1]-n1[1]*n2[0] <= 0 {
+			continue
+		}
+		o1, o2 := offset(v, n1), offset(v, n2)
+		switch opts.Join {
+		case JoinRound:
+			join := [][]float64{v, o1}
+			start := math.Atan2(n1[1], n1[0])
+			turn := math.Acos(math.Max(-1, math.Min(1, n1[0]*n2[0]+n1[1]*n2[1])))
+			steps := int(math.Ceil(turn / (math.Pi / 2 / float64(opts.QuadrantSegments))))
+			for k := 1; k < steps; k++ {
+				angle := start + turn*float64(k)/float64(steps)
+				join = append(join, []float64{v[0] + d*math.Cos(angle), v[1] + d*math.Sin(angle)})
+			}
+			join = append(join, o2, v)
+			pieces = append(pieces, [][][]float64{join})
+		case JoinMitre:
+			cos := n1[0]*n2[0] + n1[1]*n2[1]
+			if ratio := math.Sqrt(2 / (1 + cos)); 1+cos > 0 && ratio <= opts.MitreLimit {
+				mitre := []float64{v[0] + d*(n1[0]+n2[0])/(1+cos), v[1] + d*(n1[1]+n2[1])/(1+cos)}
+				pieces = append(pieces, [][][]float64{{v, o1, mitre, o2, v}})
+				continue
+			}
+			pieces = append(pieces, [][][]float64{{v, o1, o2, v}})
+		case JoinBevel:
+			pieces = append(pieces, [][][]float64{{v, o1, o2, v}})
+		}
+	}
+	return pieces
+}
+
+// geomBounds returns minX, minY, maxX, maxY of the positions of a geometry,
+// and false if it has none.
+func geomBounds(geom Geom) ([4]float64, bool) {
+	bounds := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	found := false
+	for _, poly := range geom {
+		for _, ring := range poly {
+			for _, pos := range ring {
+				bounds[0] = math.Min(bounds[0], pos[0])
+				bounds[1] = math.Min(bounds[1], pos[1])
+				bounds[2] = math.Max(bounds[2], pos[0])
+				bounds[3] = math.Max(bounds[3], pos[1])
+				found = true
+			}
+		}
+	}
+	return bounds, found
+}
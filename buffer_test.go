@@ -0,0 +1,58 @@
+package polygol
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	square := Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}}
+	// clockwise, which Buffer doesn't mind
+	lShape := Geom{{{{0, 0}, {0, 4}, {2, 4}, {2, 2}, {4, 2}, {4, 0}, {0, 0}}}}
+	// a regular polygon of 32 sides around each corner
+	roundCorners := 16 * math.Sin(2*math.Pi/32)
+
+	testCases := []struct {
+		name     string
+		geom     Geom
+		distance float64
+		opts     BufferOptions
+		area     float64
+	}{
+		{"round", square, 1, BufferOptions{}, 16 + 16 + roundCorners},
+		{"mitre", square, 1, BufferOptions{Join: JoinMitre}, 36},
+		{"bevel", square, 1, BufferOptions{Join: JoinBevel}, 36 - 2},
+		{"mitre over the limit", square, 1, BufferOptions{Join: JoinMitre, MitreLimit: 1.2}, 36 - 2},
+		{"coarse round", square, 1, BufferOptions{QuadrantSegments: 1}, 16 + 16 + 2},
+		{"shrink", square, -1, BufferOptions{}, 4},
+		{"shrink away", square, -2, BufferOptions{}, 0},
+		{"zero", square, 0, BufferOptions{}, 16},
+		// the reflex corner of the L rounds off when shrinking, as a quarter
+		// of a 32 sided polygon
+		{"shrink round", lShape, -0.5, BufferOptions{}, 12 - 7 + 0.25 - roundCorners/4/4},
+		{"shrink mitre", lShape, -0.5, BufferOptions{Join: JoinMitre}, 12 - 7},
+		// the hole shrinks as the square grows
+		{"hole", Geom{{square[0][0], {{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}}}}, 0.5, BufferOptions{Join: JoinMitre}, 25 - 1},
+	}
+
+	for _, tc := range testCases {
+		result, err := Buffer(tc.geom, tc.distance, tc.opts)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if area := result.Area(); math.Abs(area-tc.area) > 1e-9 {
+			t.Errorf("%s: expected area %v, got %v", tc.name, tc.area, area)
+		}
+	}
+}
+
+func TestBufferErrors(t *testing.T) {
+	square := Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}}
+	if _, err := Buffer(square, math.NaN(), BufferOptions{}); err == nil {
+		t.Errorf("expected an error for a NaN distance")
+	}
+	if _, err := Buffer(square, 1, BufferOptions{Join: JoinStyle(7)}); err == nil {
+		t.Errorf("expected an error for an unknown join")
+	}
+}
@@ -0,0 +1,394 @@
+// Command polygol runs boolean operations on, or validates, polygons read from
+// GeoJSON or WKT files.
+//
+// Usage:
+//
+//	polygol union|intersection|difference|xor [flags] [file ...]
+//	polygol validate [flags] [file ...]
+//
+// Every file is one input geometry, made of all of the Polygons and
+// MultiPolygons in it. A file named "-", or no file at all, reads standard
+// input. The result is written to standard output unless -o is given.
+//
+// Exit codes: 0 on success, 1 if the operation failed, 2 on bad usage and 3
+// on invalid input, including geometries that don't pass validate.
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/engelsjk/polygol"
+	"github.com/engelsjk/polygol/encoding"
+	"github.com/engelsjk/polygol/geojson"
+)
+
+const (
+	exitOK           = 0
+	exitFailure      = 1
+	exitUsage        = 2
+	exitInvalidInput = 3
+)
+
+const usage = `usage: polygol union|intersection|difference|xor|validate [flags] [file ...]
+
+Runs a boolean operation on, or validates, the polygons of GeoJSON or WKT
+files. Every file is one input geometry; "-" or no file reads standard input.
+
+flags:
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type options struct {
+	output      string
+	format      string
+	fillRule    string
+	orientation string
+	canonical   bool
+	precision   float64
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("polygol", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	opts := options{}
+	fs.StringVar(&opts.output, "o", "", "write the result to `file` instead of standard output")
+	fs.StringVar(&opts.format, "format", "", "output `format`: geojson, wkt or wkb (hex), from the -o extension by default, else geojson")
+	fs.StringVar(&opts.fillRule, "fill-rule", "nonzero", "fill `rule` of the input rings: nonzero or evenodd")
+	fs.StringVar(&opts.orientation, "orientation", "ccw", "`winding` of the exterior rings of the result: ccw, cw or input")
+	fs.BoolVar(&opts.canonical, "canonical", false, "start rings at their smallest position and sort holes and polygons, for diffing results")
+	fs.Float64Var(&opts.precision, "precision", 0, "`size` of the grid the input coordinates are rounded to, 0 for exact")
+
+	if len(args) == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	command := args[0]
+
+	// flags may come before, between or after the files
+	files := []string{}
+	rest := args[1:]
+	for {
+		if err := fs.Parse(rest); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return exitOK
+			}
+			return exitUsage
+		}
+		rest = fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		files = append(files, rest[0])
+		rest = rest[1:]
+	}
+
+	p := polygol.New()
+	switch opts.fillRule {
+	case "nonzero":
+		p.FillRule = polygol.NonZero
+	case "evenodd":
+		p.FillRule = polygol.EvenOdd
+	default:
+		fmt.Fprintf(stderr, "polygol: unknown fill rule %q\n", opts.fillRule)
+		return exitUsage
+	}
+	switch opts.orientation {
+	case "ccw":
+		p.Orientation = polygol.ExteriorCCW
+	case "cw":
+		p.Orientation = polygol.ExteriorCW
+	case "input":
+		p.Orientation = polygol.PreserveInput
+	default:
+		fmt.Fprintf(stderr, "polygol: unknown orientation %q\n", opts.orientation)
+		return exitUsage
+	}
+	p.Canonical = opts.canonical
+	if opts.precision < 0 {
+		fmt.Fprintf(stderr, "polygol: precision must not be negative\n")
+		return exitUsage
+	}
+
+	format := opts.format
+	if format == "" {
+		format = formatFromPath(opts.output)
+	}
+	switch format {
+	case "geojson", "wkt", "wkb":
+	default:
+		fmt.Fprintf(stderr, "polygol: unknown output format %q\n", format)
+		return exitUsage
+	}
+
+	var op func(geom polygol.Geom, moreGeoms ...polygol.Geom) (polygol.Geom, error)
+	switch command {
+	case "union":
+		op = p.Union
+	case "intersection":
+		op = p.Intersection
+	case "difference":
+		op = p.Difference
+	case "xor":
+		op = p.XOR
+	case "validate":
+	default:
+		fmt.Fprintf(stderr, "polygol: unknown command %q\n", command)
+		fs.Usage()
+		return exitUsage
+	}
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	geoms := make([]polygol.Geom, len(files))
+	for i, file := range files {
+		geom, err := readGeom(file, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "polygol: %s: %v\n", file, err)
+			return exitInvalidInput
+		}
+		if opts.precision > 0 {
+			geom = snapToGrid(geom, opts.precision)
+		}
+		// validate reports these along with everything else
+		if command != "validate" {
+			if err := checkStructure(geom); err != nil {
+				fmt.Fprintf(stderr, "polygol: %s: %v\n", file, err)
+				return exitInvalidInput
+			}
+		}
+		geoms[i] = geom
+	}
+
+	// the output is only created once there's something to write to it
+	var write func(w io.Writer) error
+	code := exitOK
+	if command == "validate" {
+		lines := []string{}
+		for i, geom := range geoms {
+			issues, err := p.Validate(geom)
+			if err != nil {
+				fmt.Fprintf(stderr, "polygol: validate failed: %s: %v\n", files[i], err)
+				return exitFailure
+			}
+			for _, issue := range issues {
+				lines = append(lines, fmt.Sprintf("%s: %s\n", files[i], issue))
+			}
+		}
+		if len(lines) > 0 {
+			code = exitInvalidInput
+		}
+		write = func(w io.Writer) error {
+			_, err := io.WriteString(w, strings.Join(lines, ""))
+			return err
+		}
+	} else {
+		result, err := op(geoms[0], geoms[1:]...)
+		if err != nil {
+			fmt.Fprintf(stderr, "polygol: %s failed: %v\n", command, err)
+			return exitFailure
+		}
+		write = func(w io.Writer) error {
+			return writeGeom(w, result, format)
+		}
+	}
+
+	if err := writeOutput(opts.output, stdout, write); err != nil {
+		fmt.Fprintf(stderr, "polygol: %v\n", err)
+		return exitFailure
+	}
+	return code
+}
+
+func writeOutput(path string, stdout io.Writer, write func(w io.Writer) error) error {
+	if path == "" {
+		return write(stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func formatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wkt":
+		return "wkt"
+	case ".wkb":
+		return "wkb"
+	}
+	return "geojson"
+}
+
+func readGeom(file string, stdin io.Reader) (polygol.Geom, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("no geometry")
+	}
+	if data[0] == '{' {
+		return parseGeoJSON(data)
+	}
+	return encoding.ParseWKT(string(data))
+}
+
+// parseGeoJSON reads all of the polygons of a FeatureCollection, a Feature or
+// a bare geometry.
+func parseGeoJSON(data []byte) (polygol.Geom, error) {
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	geometries := []*geojson.Geometry{}
+	switch object.Type {
+	case geojson.TypeFeatureCollection:
+		fc, err := geojson.UnmarshalFeatureCollection(data)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fc.Features {
+			if f != nil {
+				geometries = append(geometries, f.Geometry)
+			}
+		}
+	case geojson.TypeFeature:
+		f, err := geojson.UnmarshalFeature(data)
+		if err != nil {
+			return nil, err
+		}
+		geometries = append(geometries, f.Geometry)
+	default:
+		g, err := geojson.UnmarshalGeometry(data)
+		if err != nil {
+			return nil, err
+		}
+		geometries = append(geometries, g)
+	}
+
+	geom := polygol.Geom{}
+	for i, g := range geometries {
+		if g == nil {
+			continue
+		}
+		polys, err := g.Geom()
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+		geom = append(geom, polys...)
+	}
+	return geom, nil
+}
+
+// checkStructure returns the first problem that keeps a geometry from being
+// turned into rings at all, which the operations would either fail on or
+// skip the geometry for, depending on where it comes in the arguments. It
+// looks at the rings one by one, leaving how they meet to the operation.
+func checkStructure(geom polygol.Geom) error {
+	for i, poly := range geom {
+		if len(poly) == 0 {
+			return fmt.Errorf("%s (polygon %d)", polygol.TooFewPoints, i)
+		}
+		for j, ring := range poly {
+			distinct := [][]float64{}
+			for _, pos := range ring {
+				if len(pos) < 2 {
+					return fmt.Errorf("%s (polygon %d, ring %d)", polygol.InvalidCoordinates, i, j)
+				}
+				if n := len(distinct); n == 0 || pos[0] != distinct[n-1][0] || pos[1] != distinct[n-1][1] {
+					distinct = append(distinct, pos)
+				}
+			}
+			// the closing position doesn't count
+			if n := len(distinct); n > 1 && distinct[0][0] == distinct[n-1][0] && distinct[0][1] == distinct[n-1][1] {
+				distinct = distinct[:n-1]
+			}
+			if len(distinct) < 3 {
+				return fmt.Errorf("%s (polygon %d, ring %d)", polygol.TooFewPoints, i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// snapToGrid rounds the x and y of every position to the nearest multiple of
+// size, so that coordinates closer than that come out the same.
+func snapToGrid(geom polygol.Geom, size float64) polygol.Geom {
+	out := make(polygol.Geom, len(geom))
+	for i, poly := range geom {
+		out[i] = make([][][]float64, len(poly))
+		for j, ring := range poly {
+			out[i][j] = make([][]float64, len(ring))
+			for k, pos := range ring {
+				snapped := append([]float64{}, pos...)
+				for d := 0; d < 2 && d < len(snapped); d++ {
+					snapped[d] = math.Round(snapped[d]/size) * size
+				}
+				out[i][j][k] = snapped
+			}
+		}
+	}
+	return out
+}
+
+func writeGeom(w io.Writer, geom polygol.Geom, format string) error {
+	var data []byte
+	switch format {
+	case "wkt":
+		wkt, err := encoding.MarshalWKT(geom)
+		if err != nil {
+			return err
+		}
+		data = []byte(wkt + "\n")
+	case "wkb":
+		wkb, err := encoding.MarshalWKB(geom, 0)
+		if err != nil {
+			return err
+		}
+		data = []byte(hex.EncodeToString(wkb) + "\n")
+	default:
+		var err error
+		data, err = json.Marshal(geojson.NewFeature(geojson.NewMultiPolygonGeometry(geom)))
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	}
+	_, err := w.Write(data)
+	return err
+}
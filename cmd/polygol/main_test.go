@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.wkt", "POLYGON ((0 0, 2 0, 2 2, 0 2, 0 0))\n")
+	b := writeFile(t, dir, "b.geojson", `{"type":"FeatureCollection","features":[`+
+		`{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[1,1],[3,1],[3,3],[1,3],[1,1]]]},"properties":null},`+
+		`{"type":"Feature","geometry":null,"properties":null}]}`)
+	bowtie := writeFile(t, dir, "bowtie.wkt", "POLYGON ((0 0, 2 2, 2 0, 0 2, 0 0))")
+	point := writeFile(t, dir, "point.geojson", `{"type":"Point","coordinates":[0,0]}`)
+	empty := writeFile(t, dir, "empty.geojson", `{"type":"Polygon","coordinates":[[]]}`)
+	short := writeFile(t, dir, "short.wkt", "POLYGON ((0 0, 2 0, 0 0))")
+	repeated := writeFile(t, dir, "repeated.wkt", "POLYGON ((0 0, 2 0, 2 0, 0 0, 0 0))")
+
+	testCases := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+	}{
+		{"union", []string{"union", a, b, "-format", "wkt"}, "", exitOK,
+			"POLYGON ((0 0,2 0,2 1,3 1,3 3,1 3,1 2,0 2,0 0))\n"},
+		{"clockwise", []string{"union", a, b, "-format", "wkt", "-orientation", "cw"}, "", exitOK,
+			"POLYGON ((0 0,0 2,1 2,1 3,3 3,3 1,2 1,2 0,0 0))\n"},
+		{"canonical", []string{"union", "-canonical", "-format", "wkt"}, "MULTIPOLYGON (((0 0, 2 -2, 2 -1, 0 0)), ((0 0, 1 1, 0 2, 0 0)))", exitOK,
+			"MULTIPOLYGON (((0 0,1 1,0 2,0 0)),((0 0,2 -2,2 -1,0 0)))\n"},
+		{"flags first", []string{"intersection", "-format=wkt", a, b}, "", exitOK,
+			"POLYGON ((1 1,2 1,2 2,1 2,1 1))\n"},
+		{"stdin", []string{"difference", "-", b, "-format", "wkt"}, "POLYGON ((0 0, 2 0, 2 2, 0 2, 0 0))", exitOK,
+			"POLYGON ((0 0,2 0,2 1,1 1,1 2,0 2,0 0))\n"},
+		{"geojson", []string{"xor", a, a}, "", exitOK,
+			`{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[]},"properties":{}}` + "\n"},
+		{"valid", []string{"validate", a, b}, "", exitOK, ""},
+		{"invalid", []string{"validate", a, bowtie}, "", exitInvalidInput,
+			bowtie + ": self-intersection at [1.000000, 1.000000] (polygon 0, ring 0)\n"},
+		{"unreadable input", []string{"union", filepath.Join(dir, "missing.wkt")}, "", exitInvalidInput, ""},
+		{"unsupported input", []string{"union", a, point}, "", exitInvalidInput, ""},
+		{"empty input", []string{"union"}, "  ", exitInvalidInput, ""},
+		{"empty ring first", []string{"union", empty, a}, "", exitInvalidInput, ""},
+		{"empty ring later", []string{"union", a, empty}, "", exitInvalidInput, ""},
+		{"too few points later", []string{"difference", a, short}, "", exitInvalidInput, ""},
+		{"repeated points first", []string{"union", repeated, a}, "", exitInvalidInput, ""},
+		{"bowtie", []string{"union", bowtie, "-format", "wkt"}, "", exitOK,
+			"MULTIPOLYGON (((0 0,1 1,0 2,0 0)),((1 1,2 0,2 2,1 1)))\n"},
+		{"no command", []string{}, "", exitUsage, ""},
+		{"unknown command", []string{"buffer", a}, "", exitUsage, ""},
+		{"unknown flag", []string{"union", a, "-frob"}, "", exitUsage, ""},
+		{"unknown format", []string{"union", a, "-format", "svg"}, "", exitUsage, ""},
+		{"unknown fill rule", []string{"union", a, "-fill-rule", "winding"}, "", exitUsage, ""},
+		{"unknown orientation", []string{"union", a, "-orientation", "left"}, "", exitUsage, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+			if code != tc.code {
+				t.Errorf("expected exit code %d, got %d: %s", tc.code, code, stderr.String())
+			}
+			if stdout.String() != tc.stdout {
+				t.Errorf("expected output %q, got %q", tc.stdout, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.wkt", "MULTIPOLYGON (((0 0, 2 0, 2 2, 0 2, 0 0)), ((1 1, 3 1, 3 3, 1 3, 1 1)))")
+	out := filepath.Join(dir, "out.wkt")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"union", a, "-o", out, "-fill-rule", "evenodd"}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected no output, got %q", stdout.String())
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the format follows the extension, and even-odd leaves out the overlap
+	expected := "MULTIPOLYGON (((0 0,2 0,2 1,1 1,1 2,0 2,0 0)),((1 2,2 2,2 1,3 1,3 3,1 3,1 2)))\n"
+	if string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, data)
+	}
+
+	// no output file is left behind when the input is invalid
+	missing := filepath.Join(dir, "missing.wkt")
+	out = filepath.Join(dir, "none.wkt")
+	if code := run([]string{"union", missing, "-o", out}, nil, &stdout, &stderr); code != exitInvalidInput {
+		t.Errorf("expected exit code %d, got %d", exitInvalidInput, code)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("expected no output file, got %v", err)
+	}
+}
+
+func TestRunInvalidStructure(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.wkt", "POLYGON ((0 0, 2 0, 2 2, 0 2, 0 0))")
+	empty := writeFile(t, dir, "empty.geojson", `{"type":"Polygon","coordinates":[[]]}`)
+
+	// wherever it comes, the bad file is named rather than failing the
+	// operation or being left out of it
+	for _, args := range [][]string{{"union", empty, a}, {"union", a, empty}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, nil, &stdout, &stderr); code != exitInvalidInput {
+			t.Errorf("%v: expected exit code %d, got %d", args, exitInvalidInput, code)
+		}
+		if !strings.HasPrefix(stderr.String(), "polygol: "+empty+": too few points") {
+			t.Errorf("%v: expected the empty file to be named, got %q", args, stderr.String())
+		}
+	}
+}
+
+func TestRunPrecision(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.wkt", "POLYGON ((0 0, 2 0, 2 2, 0 2, 0 0))")
+	b := writeFile(t, dir, "b.wkt", "POLYGON ((2.001 0, 4 0, 4 2, 2.001 2, 2.001 0))")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"union", a, b, "-precision", "0.01", "-format", "wkt"}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr.String())
+	}
+	// the gap between the squares closes
+	if !strings.HasPrefix(stdout.String(), "POLYGON ") {
+		t.Errorf("expected a single polygon, got %q", stdout.String())
+	}
+
+	// and stays open without it, the precision being no state of polygol's
+	stdout.Reset()
+	if code := run([]string{"union", a, b, "-format", "wkt"}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "MULTIPOLYGON ") {
+		t.Errorf("expected two polygons, got %q", stdout.String())
+	}
+
+	if code := run([]string{"union", a, "-precision", "-1"}, nil, &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected exit code %d, got %d", exitUsage, code)
+	}
+}
@@ -0,0 +1,365 @@
+package polygol
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// A coverage is a set of geometries, like the counties of a state, that
+// share boundaries without overlapping. Where neighbours have the same
+// positions along their shared edges, every edge inside the coverage shows
+// up twice, once for each side, and CoverageUnion drops those pairs before
+// the sweep. Whatever is left, the outer boundary of the coverage and the
+// boundaries of its holes, is walked into rings and unioned under the
+// even-odd rule, which is what the pairs of edges would have come to anyway.
+// Overlapping geometries would come out with their overlaps left out, or
+// cancelled out entirely where they're the same, so when the union has less
+// area than the geometries together, it's an error instead.
+// Edges that neighbours share without sharing positions, or overlap
+// partially, are left to the sweep, which gets them right under the same
+// rule.
+//
+// Gaps and overlaps are found by the sweep too: overlaps are wherever two or
+// more of the geometries are represented, and gaps are the holes of the
+// union of the coverage.
+//
+// SnapCoverage moves the positions of every geometry onto those of the
+// others within the tolerance, first to their positions and then onto their
+// edges, where the position is added to the edge too, so that near
+// coincident boundaries become shared exactly.
+
+func (p *Polygol) CoverageUnion(coverage []Geom) (Geom, error) {
+	type edgeKey [2]xy
+	counts := make(map[edgeKey]int)
+	order := []edgeKey{}
+	for _, geom := range coverage {
+		for _, poly := range geom {
+			for _, ring := range poly {
+				for _, pos := range ring {
+					if len(pos) < 2 {
+						return nil, fmt.Errorf(`input geometry is not a valid polygon or multipolygon (missing coordinates)`)
+					}
+				}
+				pts := openRing(ring)
+				if len(pts) < 3 {
+					continue
+				}
+				for i := range pts {
+					a, b := toXY(pts[i]), toXY(pts[(i+1)%len(pts)])
+					if b.less(a) {
+						a, b = b, a
+					}
+					key := edgeKey{a, b}
+					if counts[key] == 0 {
+						order = append(order, key)
+					}
+					counts[key]++
+				}
+			}
+		}
+	}
+
+	// the edges left after dropping pairs meet an even number of times at
+	// every position, so walking them always leads back to the start
+	edges := []edgeKey{}
+	at := make(map[xy][]int)
+	for _, key := range order {
+		if counts[key]%2 == 1 {
+			at[key[0]] = append(at[key[0]], len(edges))
+			at[key[1]] = append(at[key[1]], len(edges))
+			edges = append(edges, key)
+		}
+	}
+	used := make([]bool, len(edges))
+	rings := Geom{}
+	for i, edge := range edges {
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		start, cur := edge[0], edge[1]
+		ring := [][]float64{{start[0], start[1]}, {cur[0], cur[1]}}
+		for cur != start {
+			next := -1
+			for _, j := range at[cur] {
+				if !used[j] {
+					next = j
+					break
+				}
+			}
+			if next == -1 {
+				return nil, fmt.Errorf("unbalanced coverage boundary at [%f,%f]", cur[0], cur[1])
+			}
+			used[next] = true
+			if edges[next][0] == cur {
+				cur = edges[next][1]
+			} else {
+				cur = edges[next][0]
+			}
+			ring = append(ring, []float64{cur[0], cur[1]})
+		}
+		rings = append(rings, [][][]float64{ring})
+	}
+	union := Geom{}
+	if len(rings) > 0 {
+		evenOdd := *p
+		evenOdd.FillRule = EvenOdd
+		var err error
+		if union, err = evenOdd.run("union", rings, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// overlaps are covered once by the union but counted by every geometry
+	// covering them, where the pairs of their edges didn't cancel out
+	area := 0.0
+	for _, geom := range coverage {
+		area += geom.Area()
+	}
+	if union.Area() < area*(1-1e-9) {
+		return nil, errors.New("coverage geometries overlap")
+	}
+	return p.output(union, coverage), nil
+}
+
+func (p *Polygol) CoverageOverlaps(coverage []Geom) (Geom, error) {
+	if len(coverage) < 2 {
+		return Geom{}, nil
+	}
+	return p.result("overlap", coverage[0], coverage[1:])
+}
+
+func (p *Polygol) CoverageGaps(coverage []Geom) (Geom, error) {
+	if len(coverage) == 0 {
+		return Geom{}, nil
+	}
+	union, err := p.run("union", coverage[0], coverage[1:])
+	if err != nil {
+		return nil, err
+	}
+	gaps := Geom{}
+	for _, poly := range union {
+		for _, hole := range poly[1:] {
+			gaps = append(gaps, [][][]float64{reversePositions(hole)})
+		}
+	}
+	return p.output(gaps, coverage), nil
+}
+
+func (p *Polygol) SnapCoverage(coverage []Geom, tolerance float64) ([]Geom, error) {
+	if math.IsNaN(tolerance) || math.IsInf(tolerance, 0) || tolerance < 0 {
+		return nil, fmt.Errorf("invalid snapping tolerance %v", tolerance)
+	}
+	for _, geom := range coverage {
+		for _, poly := range geom {
+			for _, ring := range poly {
+				for _, pos := range ring {
+					if len(pos) < 2 {
+						return nil, fmt.Errorf(`input geometry is not a valid polygon or multipolygon (missing coordinates)`)
+					}
+				}
+			}
+		}
+	}
+
+	type ringRef struct{ geom, poly, ring int }
+	rings := make(map[ringRef][][]float64)
+	refs := []ringRef{}
+	for i, geom := range coverage {
+		for j, poly := range geom {
+			for k, ring := range poly {
+				ref := ringRef{i, j, k}
+				rings[ref] = openRing(ring)
+				refs = append(refs, ref)
+			}
+		}
+	}
+	if tolerance == 0 {
+		return p.outputCoverage(rebuildCoverage(coverage, func(i, j, k int) [][]float64 {
+			return rings[ringRef{i, j, k}]
+		}), coverage), nil
+	}
+
+	// positions snap to the first position within the tolerance
+	cellOf := func(pos xy, size float64) [2]int64 {
+		return [2]int64{int64(math.Floor(pos[0] / size)), int64(math.Floor(pos[1] / size))}
+	}
+	anchors := make(map[[2]int64][]xy)
+	snapped := make(map[xy]xy)
+	for _, ref := range refs {
+		for _, pos := range rings[ref] {
+			key := toXY(pos)
+			if _, ok := snapped[key]; ok {
+				continue
+			}
+			snapped[key] = key
+			c := cellOf(key, tolerance)
+			found := false
+			for dx := int64(-1); dx <= 1 && !found; dx++ {
+				for dy := int64(-1); dy <= 1 && !found; dy++ {
+					for _, anchor := range anchors[[2]int64{c[0] + dx, c[1] + dy}] {
+						if math.Hypot(anchor[0]-key[0], anchor[1]-key[1]) <= tolerance {
+							snapped[key] = anchor
+							found = true
+							break
+						}
+					}
+				}
+			}
+			if !found {
+				anchors[c] = append(anchors[c], key)
+			}
+		}
+	}
+
+	// the edges of the rings at their snapped positions, in a grid of cells
+	// about as large as the edges
+	type snapEdge struct {
+		ref   ringRef
+		index int
+		a, b  xy
+	}
+	edges := []snapEdge{}
+	onRing := make(map[xy]map[ringRef]bool)
+	length := 0.0
+	for _, ref := range refs {
+		pts := rings[ref]
+		for k := range pts {
+			a, b := snapped[toXY(pts[k])], snapped[toXY(pts[(k+1)%len(pts)])]
+			if onRing[a] == nil {
+				onRing[a] = make(map[ringRef]bool)
+			}
+			onRing[a][ref] = true
+			if a == b {
+				continue
+			}
+			edges = append(edges, snapEdge{ref: ref, index: k, a: a, b: b})
+			length += math.Hypot(b[0]-a[0], b[1]-a[1])
+		}
+	}
+	size := tolerance
+	if len(edges) > 0 {
+		size = math.Max(tolerance, length/float64(len(edges)))
+	}
+	grid := make(map[[2]int64][]int)
+	for e, edge := range edges {
+		ll := cellOf(xy{math.Min(edge.a[0], edge.b[0]) - tolerance, math.Min(edge.a[1], edge.b[1]) - tolerance}, size)
+		ur := cellOf(xy{math.Max(edge.a[0], edge.b[0]) + tolerance, math.Max(edge.a[1], edge.b[1]) + tolerance}, size)
+		for x := ll[0]; x <= ur[0]; x++ {
+			for y := ll[1]; y <= ur[1]; y++ {
+				grid[[2]int64{x, y}] = append(grid[[2]int64{x, y}], e)
+			}
+		}
+	}
+
+	// then onto the closest edge of another geometry within the tolerance
+	type insertion struct {
+		t   float64
+		pos xy
+	}
+	moved := make(map[xy]xy)
+	inserted := make(map[ringRef]map[int][]insertion)
+	for _, ref := range refs {
+		for _, pos := range rings[ref] {
+			v := snapped[toXY(pos)]
+			if _, ok := moved[v]; ok {
+				continue
+			}
+			moved[v] = v
+			best, bestDist, bestT := -1, math.Inf(1), 0.0
+			for _, e := range grid[cellOf(v, size)] {
+				edge := edges[e]
+				if edge.ref.geom == ref.geom || onRing[v][edge.ref] {
+					continue
+				}
+				dx, dy := edge.b[0]-edge.a[0], edge.b[1]-edge.a[1]
+				t := ((v[0]-edge.a[0])*dx + (v[1]-edge.a[1])*dy) / (dx*dx + dy*dy)
+				if t <= 0 || t >= 1 {
+					continue
+				}
+				if d := math.Hypot(edge.a[0]+t*dx-v[0], edge.a[1]+t*dy-v[1]); d <= tolerance && d < bestDist {
+					best, bestDist, bestT = e, d, t
+				}
+			}
+			if best == -1 {
+				continue
+			}
+			edge := edges[best]
+			q := xy{edge.a[0] + bestT*(edge.b[0]-edge.a[0]), edge.a[1] + bestT*(edge.b[1]-edge.a[1])}
+			moved[v] = q
+			if inserted[edge.ref] == nil {
+				inserted[edge.ref] = make(map[int][]insertion)
+			}
+			inserted[edge.ref][edge.index] = append(inserted[edge.ref][edge.index], insertion{t: bestT, pos: q})
+		}
+	}
+
+	return p.outputCoverage(rebuildCoverage(coverage, func(i, j, k int) [][]float64 {
+		ref := ringRef{i, j, k}
+		pts := rings[ref]
+		out := [][]float64{}
+		for e, pos := range pts {
+			v := moved[snapped[toXY(pos)]]
+			out = append(out, []float64{v[0], v[1]})
+			ins := inserted[ref][e]
+			sort.Slice(ins, func(a, b int) bool { return ins[a].t < ins[b].t })
+			for _, in := range ins {
+				out = append(out, []float64{in.pos[0], in.pos[1]})
+			}
+		}
+		return out
+	}), coverage), nil
+}
+
+// rebuildCoverage puts the open rings of a coverage back together, dropping
+// repeated positions, spikes and rings left with fewer than three positions,
+// along with the polygons whose exterior ring went.
+func rebuildCoverage(coverage []Geom, ring func(i, j, k int) [][]float64) []Geom {
+	out := make([]Geom, len(coverage))
+	for i, geom := range coverage {
+		out[i] = Geom{}
+		for j, poly := range geom {
+			outPoly := [][][]float64{}
+			for k := range poly {
+				pts := removeSpikes(openRing(ring(i, j, k)))
+				if len(pts) < 3 {
+					if k == 0 {
+						break
+					}
+					continue
+				}
+				outPoly = append(outPoly, append(pts, append([]float64{}, pts[0]...)))
+			}
+			if len(outPoly) > 0 {
+				out[i] = append(out[i], outPoly)
+			}
+		}
+	}
+	return out
+}
+
+// removeSpikes drops the positions of an open ring where it goes straight
+// back the way it came.
+func removeSpikes(pts [][]float64) [][]float64 {
+	for changed := true; changed && len(pts) >= 3; {
+		changed = false
+		n := len(pts)
+		for i := range pts {
+			if toXY(pts[(i+n-1)%n]) == toXY(pts[(i+1)%n]) {
+				// drop the spike and one of its equal neighbours
+				keep := [][]float64{}
+				for k := range pts {
+					if k != i && k != (i+1)%n {
+						keep = append(keep, pts[k])
+					}
+				}
+				pts = openRing(keep)
+				changed = true
+				break
+			}
+		}
+	}
+	return pts
+}
@@ -0,0 +1,133 @@
+package polygol
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCoverageUnion(t *testing.T) {
+	// a 2 by 2 grid of squares, the top right one with a hole filled by an
+	// island of its own
+	coverage := []Geom{
+		{{rect(0, 0, 1, 1)}},
+		{{rect(1, 0, 2, 1)}},
+		{{rect(0, 1, 1, 2)}},
+		{{rect(1, 1, 2, 2), reversePositions(rect(1.25, 1.25, 1.75, 1.75))}},
+		{{rect(1.25, 1.25, 1.75, 1.75)}},
+	}
+	result, err := CoverageUnion(coverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || len(result[0]) != 1 || len(result[0][0]) != 5 {
+		t.Errorf("expected a single square, got %v", result)
+	}
+	if area := result.Area(); area != 4 {
+		t.Errorf("expected area 4, got %v", area)
+	}
+
+	// without the island, its hole is a hole of the union
+	result, err = CoverageUnion(coverage[:4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || len(result[0]) != 2 || result.Area() != 3.75 {
+		t.Errorf("expected a square with a hole, got %v", result)
+	}
+
+	// neighbours that don't share positions along their edges
+	result, err = CoverageUnion([]Geom{
+		{{rect(0, 0, 1, 2)}},
+		{{{{1, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 0}}}},
+		{{{{1, 1}, {2, 1}, {2, 2}, {1, 2}, {1, 1}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || len(result[0]) != 1 || result.Area() != 4 {
+		t.Errorf("expected a single square, got %v", result)
+	}
+}
+
+func TestCoverageUnionOverlaps(t *testing.T) {
+	for name, coverage := range map[string][]Geom{
+		"overlapping": {{{rect(0, 0, 10, 10)}}, {{rect(5, 5, 15, 15)}}},
+		"the same":    {{{rect(0, 0, 10, 10)}}, {{rect(0, 0, 10, 10)}}},
+		"within":      {{{rect(0, 0, 10, 10)}}, {{rect(2, 2, 4, 4)}}},
+	} {
+		if result, err := CoverageUnion(coverage); err == nil {
+			t.Errorf("%s: expected an error, got %v", name, result)
+		}
+	}
+}
+
+func TestCoverageGapsAndOverlaps(t *testing.T) {
+	// a frame of four rectangles around a gap, the right one overlapping
+	// the top one
+	coverage := []Geom{
+		{{rect(0, 0, 1, 3)}},
+		{{rect(2, 0, 3, 3)}},
+		{{rect(1, 0, 2, 1)}},
+		{{rect(1, 2, 2.5, 3)}},
+	}
+	gaps, err := CoverageGaps(coverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gaps) != 1 || gaps.Area() != 1 {
+		t.Errorf("expected a gap of area 1, got %v", gaps)
+	}
+	if bounds, _ := geomBounds(gaps); bounds != [4]float64{1, 1, 2, 2} {
+		t.Errorf("expected the gap between (1, 1) and (2, 2), got %v", gaps)
+	}
+
+	overlaps, err := CoverageOverlaps(coverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlaps) != 1 || overlaps.Area() != 0.5 {
+		t.Errorf("expected an overlap of area 0.5, got %v", overlaps)
+	}
+	if bounds, _ := geomBounds(overlaps); bounds != [4]float64{2, 2, 2.5, 3} {
+		t.Errorf("expected the overlap between (2, 2) and (2.5, 3), got %v", overlaps)
+	}
+}
+
+func TestSnapCoverage(t *testing.T) {
+	// a right neighbour a little off, with a position close to the middle of
+	// the left one's edge
+	coverage := []Geom{
+		{{rect(0, 0, 1, 1)}},
+		{{{{1.001, 0}, {2, 0}, {2, 1}, {1.001, 1.0005}, {1.0008, 0.5}, {1.001, 0}}}},
+	}
+	snapped, err := SnapCoverage(coverage, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	left, right := snapped[0][0][0], snapped[1][0][0]
+	if len(left) != 6 {
+		t.Errorf("expected the left square to get the position of its neighbour, got %v", left)
+	}
+	if len(right) != 6 || right[0][0] != 1 || right[3][0] != 1 || right[3][1] != 1 || right[4][0] != 1 {
+		t.Errorf("expected the right square on the edge of the left one, got %v", right)
+	}
+
+	gaps, err := CoverageGaps(snapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlaps, err := CoverageOverlaps(snapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gaps) != 0 || len(overlaps) != 0 {
+		t.Errorf("expected neither gaps nor overlaps, got %v and %v", gaps, overlaps)
+	}
+	union, err := CoverageUnion(snapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(union) != 1 || len(union[0]) != 1 || math.Abs(union.Area()-2) > 1e-12 {
+		t.Errorf("expected a single rectangle, got %v", union)
+	}
+}
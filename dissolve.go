@@ -0,0 +1,38 @@
+package polygol
+
+// splitPolys turns every polygon of a multipolygon into a Geom of its own.
+func splitPolys(geom Geom) []Geom {
+	polys := make([]Geom, len(geom))
+	for i := 0; i < len(geom); i++ {
+		polys[i] = Geom{geom[i]}
+	}
+	return polys
+}
+
+// convertPolys converts every polygon the way the operations convert their
+// inputs, returning the first error, where the operations would only fail
+// on the first of their inputs and skip the others.
+func (p *Polygol) convertPolys(polys []Geom) error {
+	o := p.newOperation("union")
+	for _, poly := range polys {
+		if _, err := o.newMultiPolyIn(poly, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupPolys collects the polygons of a multipolygon by key, along with the
+// keys in order of first appearance.
+func groupPolys(geom Geom, keys []string) (map[string]Geom, []string) {
+	groups := make(map[string]Geom)
+	order := []string{}
+	for i := 0; i < len(geom); i++ {
+		key := keys[i]
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], geom[i])
+	}
+	return groups, order
+}
@@ -0,0 +1,72 @@
+package polygol
+
+import (
+	"testing"
+)
+
+func TestDissolve(t *testing.T) {
+	// overlapping, touching and disjoint polygons
+	geom := Geom{
+		{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+		{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}},
+		{{{3, 1}, {4, 1}, {4, 2}, {3, 2}, {3, 1}}},
+		{{{6, 0}, {7, 0}, {7, 1}, {6, 1}, {6, 0}}},
+	}
+	result, err := Dissolve(geom)
+	terr(t, err)
+	expect(t, equalMultiPoly(result, Geom{
+		{{{0, 0}, {2, 0}, {2, 1}, {4, 1}, {4, 2}, {3, 2}, {3, 3}, {1, 3}, {1, 2}, {0, 2}, {0, 0}}},
+		{{{6, 0}, {7, 0}, {7, 1}, {6, 1}, {6, 0}}},
+	}))
+
+	// empty
+	result, err = Dissolve(Geom{})
+	terr(t, err)
+	expect(t, len(result) == 0)
+
+	// the fill rule applies per polygon: the hole of the first polygon
+	// is covered by the second one
+	evenOdd := &Polygol{FillRule: EvenOdd}
+	geom = Geom{
+		{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, {{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}}},
+		{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}},
+	}
+	result, err = evenOdd.Dissolve(geom)
+	terr(t, err)
+	expect(t, equalMultiPoly(result, Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}}))
+}
+
+func TestDissolveInvalid(t *testing.T) {
+	good := [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}
+	bad := [][][]float64{{{2, 0}, {3, 0}, {3}, {2, 1}, {2, 0}}}
+
+	// an invalid polygon is an error wherever it comes
+	for name, geom := range map[string]Geom{
+		"first":  {bad, good},
+		"second": {good, bad},
+	} {
+		if _, err := Dissolve(geom); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if _, err := DissolveByKey(geom, []string{"a", "a"}); err == nil {
+			t.Errorf("%s: expected an error by key", name)
+		}
+	}
+}
+
+func TestDissolveByKey(t *testing.T) {
+	geom := Geom{
+		{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+		{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}},
+		{{{2, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 0}}},
+	}
+	results, err := DissolveByKey(geom, []string{"a", "b", "a"})
+	terr(t, err)
+	expect(t, len(results) == 2)
+	expect(t, equalMultiPoly(results["a"], Geom{{{{0, 0}, {4, 0}, {4, 2}, {0, 2}, {0, 0}}}}))
+	expect(t, equalMultiPoly(results["b"], Geom{{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}}))
+
+	// mismatched keys
+	_, err = DissolveByKey(geom, []string{"a"})
+	expect(t, err != nil)
+}
@@ -0,0 +1,233 @@
+package encoding
+
+// Well-known binary (WKB) for Polygons and MultiPolygons, in both the ISO and
+// the extended (EWKB) flavors used by PostGIS. Z and M coordinates are read
+// but dropped, since polygol works in two dimensions.
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/engelsjk/polygol"
+)
+
+const (
+	wkbPolygon      = 3
+	wkbMultiPolygon = 6
+
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// ParseWKB parses a WKB or EWKB Polygon or MultiPolygon, along with its SRID
+// if it has one and 0 otherwise.
+func ParseWKB(b []byte) (polygol.Geom, int, error) {
+	r := &wkbReader{data: b}
+	geomType, dims, srid, err := r.header()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	geom := polygol.Geom{}
+	switch geomType {
+	case wkbPolygon:
+		poly, err := r.polygon(dims)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(poly) > 0 {
+			geom = append(geom, poly)
+		}
+	case wkbMultiPolygon:
+		n, err := r.count(9)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := 0; i < n; i++ {
+			polyType, polyDims, _, err := r.header()
+			if err != nil {
+				return nil, 0, err
+			}
+			if polyType != wkbPolygon {
+				return nil, 0, fmt.Errorf("wkb: expected a polygon in multipolygon, got geometry type %d", polyType)
+			}
+			poly, err := r.polygon(polyDims)
+			if err != nil {
+				return nil, 0, err
+			}
+			if len(poly) > 0 {
+				geom = append(geom, poly)
+			}
+		}
+	default:
+		return nil, 0, fmt.Errorf("wkb: unsupported geometry type %d", geomType)
+	}
+
+	if r.pos != len(r.data) {
+		return nil, 0, fmt.Errorf("wkb: %d unexpected bytes after geometry", len(r.data)-r.pos)
+	}
+	return geom, srid, nil
+}
+
+// MarshalWKB encodes a geometry as a little endian WKB Polygon if it has
+// exactly one polygon and as a MultiPolygon otherwise. A non-zero SRID makes
+// it EWKB with the SRID embedded. A position with fewer than two coordinates
+// is an error.
+func MarshalWKB(geom polygol.Geom, srid int) ([]byte, error) {
+	if err := checkPositions("wkb", geom); err != nil {
+		return nil, err
+	}
+	w := &wkbWriter{}
+	if len(geom) == 1 {
+		w.header(wkbPolygon, srid)
+		w.polygon(geom[0])
+		return w.data, nil
+	}
+	w.header(wkbMultiPolygon, srid)
+	w.uint32(uint32(len(geom)))
+	for i := 0; i < len(geom); i++ {
+		w.header(wkbPolygon, 0)
+		w.polygon(geom[i])
+	}
+	return w.data, nil
+}
+
+type wkbReader struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+// header reads the byte order and geometry type, and the SRID if the type
+// has the EWKB flag for it.
+func (r *wkbReader) header() (geomType uint32, dims int, srid int, err error) {
+	if r.pos >= len(r.data) {
+		return 0, 0, 0, fmt.Errorf("wkb: unexpected end of data at offset %d", r.pos)
+	}
+	switch r.data[r.pos] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return 0, 0, 0, fmt.Errorf("wkb: invalid byte order %d at offset %d", r.data[r.pos], r.pos)
+	}
+	r.pos++
+
+	t, err := r.uint32()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	dims = 2
+	if t&ewkbZ != 0 {
+		dims++
+	}
+	if t&ewkbM != 0 {
+		dims++
+	}
+	if t&ewkbSRID != 0 {
+		s, err := r.uint32()
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		srid = int(int32(s))
+	}
+
+	// ISO WKB adds 1000 for Z, 2000 for M and 3000 for ZM
+	t &^= ewkbZ | ewkbM | ewkbSRID
+	switch t / 1000 {
+	case 1, 2:
+		dims++
+	case 3:
+		dims += 2
+	}
+	return t % 1000, dims, srid, nil
+}
+
+func (r *wkbReader) uint32() (uint32, error) {
+	if len(r.data)-r.pos < 4 {
+		return 0, fmt.Errorf("wkb: unexpected end of data at offset %d", r.pos)
+	}
+	v := r.order.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return v, nil
+}
+
+// count reads a number of elements, each of at least size bytes, checking
+// that the data is long enough to hold them.
+func (r *wkbReader) count(size int) (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if int(n) > (len(r.data)-r.pos)/size {
+		return 0, fmt.Errorf("wkb: %d elements at offset %d exceed the data", n, r.pos)
+	}
+	return int(n), nil
+}
+
+func (r *wkbReader) polygon(dims int) ([][][]float64, error) {
+	numRings, err := r.count(4)
+	if err != nil {
+		return nil, err
+	}
+	poly := make([][][]float64, 0, numRings)
+	for i := 0; i < numRings; i++ {
+		numPoints, err := r.count(8 * dims)
+		if err != nil {
+			return nil, err
+		}
+		if numPoints == 0 {
+			continue
+		}
+		ring := make([][]float64, numPoints)
+		for j := 0; j < numPoints; j++ {
+			x := math.Float64frombits(r.order.Uint64(r.data[r.pos:]))
+			y := math.Float64frombits(r.order.Uint64(r.data[r.pos+8:]))
+			ring[j] = []float64{x, y}
+			r.pos += 8 * dims
+		}
+		poly = append(poly, ring)
+	}
+	return poly, nil
+}
+
+type wkbWriter struct {
+	data []byte
+}
+
+func (w *wkbWriter) header(geomType uint32, srid int) {
+	w.data = append(w.data, 1)
+	if srid != 0 {
+		w.uint32(geomType | ewkbSRID)
+		w.uint32(uint32(srid))
+		return
+	}
+	w.uint32(geomType)
+}
+
+func (w *wkbWriter) uint32(v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	w.data = append(w.data, b[:]...)
+}
+
+func (w *wkbWriter) float64(f float64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+	w.data = append(w.data, b[:]...)
+}
+
+func (w *wkbWriter) polygon(poly [][][]float64) {
+	w.uint32(uint32(len(poly)))
+	for i := 0; i < len(poly); i++ {
+		w.uint32(uint32(len(poly[i])))
+		for j := 0; j < len(poly[i]); j++ {
+			w.float64(poly[i][j][0])
+			w.float64(poly[i][j][1])
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package encoding
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/engelsjk/polygol"
+)
+
+func TestWKBRoundTrip(t *testing.T) {
+	for name, geom := range loadFixtures(t) {
+		for _, srid := range []int{0, 4326} {
+			data, err := MarshalWKB(geom, srid)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+			parsed, parsedSRID, err := ParseWKB(data)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+			if !reflect.DeepEqual(parsed, geom) {
+				t.Errorf("%s: round trip mismatch", name)
+			}
+			if parsedSRID != srid {
+				t.Errorf("%s: expected SRID %d, got %d", name, srid, parsedSRID)
+			}
+		}
+	}
+}
+
+func TestParseWKB(t *testing.T) {
+	triangle := polygol.Geom{{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}}
+
+	testCases := []struct {
+		name string
+		hex  string
+		geom polygol.Geom
+		srid int
+	}{
+		{"little endian polygon",
+			"01030000000100000004000000" +
+				"00000000000000000000000000000000" +
+				"000000000000f03f0000000000000000" +
+				"0000000000000000000000000000f03f" +
+				"00000000000000000000000000000000",
+			triangle, 0},
+		{"big endian polygon",
+			"00000000030000000100000004" +
+				"00000000000000000000000000000000" +
+				"3ff00000000000000000000000000000" +
+				"00000000000000003ff0000000000000" +
+				"00000000000000000000000000000000",
+			triangle, 0},
+		{"ewkb polygon with srid",
+			"0103000020e61000000100000004000000" +
+				"00000000000000000000000000000000" +
+				"000000000000f03f0000000000000000" +
+				"0000000000000000000000000000f03f" +
+				"00000000000000000000000000000000",
+			triangle, 4326},
+		{"iso polygon z",
+			"01eb0300000100000004000000" +
+				"000000000000000000000000000000000000000000001440" +
+				"000000000000f03f00000000000000000000000000001440" +
+				"0000000000000000000000000000f03f0000000000001440" +
+				"000000000000000000000000000000000000000000001440",
+			triangle, 0},
+		{"ewkb multipolygon z",
+			"010600008002000000010300008001000000040000000000000000000000000000000000000000000000000014400000" +
+				"00000000f03f000000000000000000000000000014400000000000000000000000000000f03f00000000000014400000" +
+				"00000000000000000000000000000000000000001440010300008000000000",
+			triangle, 0},
+		{"empty multipolygon", "010600000000000000", polygol.Geom{}, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tc.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+			geom, srid, err := ParseWKB(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(geom, tc.geom) {
+				t.Errorf("expected %v, got %v", tc.geom, geom)
+			}
+			if srid != tc.srid {
+				t.Errorf("expected SRID %d, got %d", tc.srid, srid)
+			}
+		})
+	}
+}
+
+func TestParseWKBErrors(t *testing.T) {
+	for _, h := range []string{
+		"",
+		"02",
+		"0101000000000000000000000000000000000000f03f", // point
+		"010300000001000000ffffff7f",                   // too many points
+		"01030000000100000001000000000000000000",       // truncated point
+		"01030000000000000000",                         // trailing bytes
+	} {
+		b, _ := hex.DecodeString(h)
+		if _, _, err := ParseWKB(b); err == nil {
+			t.Errorf("expected an error parsing %s", h)
+		}
+	}
+}
+
+func TestMarshalWKB(t *testing.T) {
+	triangle := polygol.Geom{{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}}
+	expected := "0103000020e61000000100000004000000" +
+		"00000000000000000000000000000000" +
+		"000000000000f03f0000000000000000" +
+		"0000000000000000000000000000f03f" +
+		"00000000000000000000000000000000"
+	data, err := MarshalWKB(triangle, 4326)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := hex.EncodeToString(data); h != expected {
+		t.Errorf("expected %s, got %s", expected, h)
+	}
+	data, err = MarshalWKB(polygol.Geom{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := hex.EncodeToString(data); h != "010600000000000000" {
+		t.Errorf("expected empty multipolygon, got %s", h)
+	}
+
+	// a position without a y is an error rather than a panic
+	if data, err := MarshalWKB(polygol.Geom{{{{0, 0}, {1}, {1, 1}, {0, 0}}}}, 0); err == nil {
+		t.Errorf("expected an error, got %x", data)
+	}
+}
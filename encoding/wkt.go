@@ -0,0 +1,286 @@
+package encoding
+
+// Well-known text (WKT) for Polygons and MultiPolygons, as in the OGC Simple
+// Features spec. Z and M coordinates are read but dropped, since polygol works
+// in two dimensions, and an EWKT "SRID=...;" prefix is accepted and ignored.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/engelsjk/polygol"
+)
+
+// ParseWKT parses a WKT Polygon or MultiPolygon.
+func ParseWKT(s string) (polygol.Geom, error) {
+	l := &wktLexer{input: s}
+
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(s)), "SRID=") {
+		if i := strings.IndexByte(s, ';'); i != -1 {
+			l.pos = i + 1
+		} else {
+			return nil, fmt.Errorf("wkt: SRID prefix without ';'")
+		}
+	}
+
+	geomType := strings.ToUpper(l.next())
+	dims, err := l.dims()
+	if err != nil {
+		return nil, err
+	}
+
+	var geom polygol.Geom
+	switch geomType {
+	case "POLYGON":
+		var poly [][][]float64
+		poly, err = l.polygon(dims)
+		if len(poly) > 0 {
+			geom = polygol.Geom{poly}
+		}
+	case "MULTIPOLYGON":
+		geom, err = l.multiPolygon(dims)
+	case "":
+		return nil, fmt.Errorf("wkt: empty input")
+	default:
+		return nil, fmt.Errorf("wkt: unsupported geometry type %s", geomType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tok := l.next(); tok != "" {
+		return nil, fmt.Errorf("wkt: unexpected %q after geometry", tok)
+	}
+	if geom == nil {
+		geom = polygol.Geom{}
+	}
+	return geom, nil
+}
+
+// MarshalWKT formats a geometry as a WKT Polygon if it has exactly one
+// polygon and as a MultiPolygon otherwise. A position with fewer than two
+// coordinates is an error.
+func MarshalWKT(geom polygol.Geom) (string, error) {
+	if err := checkPositions("wkt", geom); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if len(geom) == 1 {
+		sb.WriteString("POLYGON ")
+		writeWKTPolygon(&sb, geom[0])
+		return sb.String(), nil
+	}
+	sb.WriteString("MULTIPOLYGON ")
+	if len(geom) == 0 {
+		sb.WriteString("EMPTY")
+		return sb.String(), nil
+	}
+	sb.WriteByte('(')
+	for i := 0; i < len(geom); i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		writeWKTPolygon(&sb, geom[i])
+	}
+	sb.WriteByte(')')
+	return sb.String(), nil
+}
+
+// checkPositions makes sure that every position has the x and y the writers
+// index.
+func checkPositions(format string, geom polygol.Geom) error {
+	for i := 0; i < len(geom); i++ {
+		for j := 0; j < len(geom[i]); j++ {
+			for k := 0; k < len(geom[i][j]); k++ {
+				if len(geom[i][j][k]) < 2 {
+					return fmt.Errorf("%s: position %d of ring %d of polygon %d has %d coordinates, expected at least 2",
+						format, k, j, i, len(geom[i][j][k]))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func writeWKTPolygon(sb *strings.Builder, poly [][][]float64) {
+	if len(poly) == 0 {
+		sb.WriteString("EMPTY")
+		return
+	}
+	sb.WriteByte('(')
+	for i := 0; i < len(poly); i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteByte('(')
+		for j := 0; j < len(poly[i]); j++ {
+			if j > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(formatCoord(poly[i][j][0]))
+			sb.WriteByte(' ')
+			sb.WriteString(formatCoord(poly[i][j][1]))
+		}
+		sb.WriteByte(')')
+	}
+	sb.WriteByte(')')
+}
+
+// formatCoord writes the shortest representation that parses back to the
+// same float64.
+func formatCoord(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+type wktLexer struct {
+	input string
+	pos   int
+}
+
+// next returns the next token: a word or number, or one of "(),", and ""
+// at the end of the input.
+func (l *wktLexer) next() string {
+	for l.pos < len(l.input) && unicode.IsSpace(rune(l.input[l.pos])) {
+		l.pos++
+	}
+	if l.pos == len(l.input) {
+		return ""
+	}
+	start := l.pos
+	switch c := l.input[l.pos]; c {
+	case '(', ')', ',':
+		l.pos++
+		return string(c)
+	}
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if unicode.IsSpace(rune(c)) || c == '(' || c == ')' || c == ',' {
+			break
+		}
+		l.pos++
+	}
+	return l.input[start:l.pos]
+}
+
+func (l *wktLexer) peek() string {
+	pos := l.pos
+	tok := l.next()
+	l.pos = pos
+	return tok
+}
+
+func (l *wktLexer) expect(want string) error {
+	if tok := l.next(); tok != want {
+		return fmt.Errorf("wkt: expected %q, got %q at offset %d", want, tok, l.pos)
+	}
+	return nil
+}
+
+// dims reads the optional Z, M or ZM tag and returns the number of
+// coordinates per position.
+func (l *wktLexer) dims() (int, error) {
+	switch strings.ToUpper(l.peek()) {
+	case "Z", "M":
+		l.next()
+		return 3, nil
+	case "ZM":
+		l.next()
+		return 4, nil
+	}
+	return 2, nil
+}
+
+// empty consumes an EMPTY token if it's next.
+func (l *wktLexer) empty() bool {
+	if strings.ToUpper(l.peek()) == "EMPTY" {
+		l.next()
+		return true
+	}
+	return false
+}
+
+func (l *wktLexer) multiPolygon(dims int) (polygol.Geom, error) {
+	if l.empty() {
+		return nil, nil
+	}
+	if err := l.expect("("); err != nil {
+		return nil, err
+	}
+	geom := polygol.Geom{}
+	for {
+		poly, err := l.polygon(dims)
+		if err != nil {
+			return nil, err
+		}
+		if len(poly) > 0 {
+			geom = append(geom, poly)
+		}
+		if l.peek() != "," {
+			break
+		}
+		l.next()
+	}
+	return geom, l.expect(")")
+}
+
+func (l *wktLexer) polygon(dims int) ([][][]float64, error) {
+	if l.empty() {
+		return nil, nil
+	}
+	if err := l.expect("("); err != nil {
+		return nil, err
+	}
+	poly := [][][]float64{}
+	for {
+		ring, err := l.ring(dims)
+		if err != nil {
+			return nil, err
+		}
+		if len(ring) > 0 {
+			poly = append(poly, ring)
+		}
+		if l.peek() != "," {
+			break
+		}
+		l.next()
+	}
+	return poly, l.expect(")")
+}
+
+func (l *wktLexer) ring(dims int) ([][]float64, error) {
+	if l.empty() {
+		return nil, nil
+	}
+	if err := l.expect("("); err != nil {
+		return nil, err
+	}
+	ring := [][]float64{}
+	for {
+		pos, err := l.position(dims)
+		if err != nil {
+			return nil, err
+		}
+		ring = append(ring, pos)
+		if l.peek() != "," {
+			break
+		}
+		l.next()
+	}
+	return ring, l.expect(")")
+}
+
+func (l *wktLexer) position(dims int) ([]float64, error) {
+	pos := make([]float64, 0, 2)
+	for i := 0; i < dims; i++ {
+		tok := l.next()
+		f, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, fmt.Errorf("wkt: expected a coordinate, got %q at offset %d", tok, l.pos)
+		}
+		if i < 2 {
+			pos = append(pos, f)
+		}
+	}
+	return pos, nil
+}
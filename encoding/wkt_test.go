@@ -0,0 +1,174 @@
+package encoding
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/engelsjk/polygol"
+	"github.com/engelsjk/polygol/geojson"
+)
+
+const endToEndDir = "../testdata/end-to-end"
+
+// loadFixtures reads every Polygon and MultiPolygon of the end-to-end
+// fixtures, keyed by file and feature.
+func loadFixtures(t *testing.T) map[string]polygol.Geom {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(endToEndDir, "*", "*.geojson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no fixtures found")
+	}
+	fixtures := make(map[string]polygol.Geom)
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		features := []*geojson.Feature{}
+		if fc, err := geojson.UnmarshalFeatureCollection(b); err == nil && fc.Type == "FeatureCollection" {
+			features = fc.Features
+		} else if f, err := geojson.UnmarshalFeature(b); err == nil {
+			features = append(features, f)
+		}
+		for i, f := range features {
+			if f.Geometry == nil {
+				continue
+			}
+			var geom polygol.Geom
+			switch f.Geometry.Type {
+			case "Polygon":
+				geom = polygol.Geom{f.Geometry.Polygon}
+			case "MultiPolygon":
+				geom = f.Geometry.MultiPolygon
+			default:
+				continue
+			}
+			fixtures[filepath.Join(path, string(rune('a'+i)))] = normalize(geom)
+		}
+	}
+	return fixtures
+}
+
+// normalize drops what the encodings can't represent: coordinates past x and
+// y, and empty rings and polygons.
+func normalize(geom polygol.Geom) polygol.Geom {
+	out := polygol.Geom{}
+	for _, poly := range geom {
+		outPoly := [][][]float64{}
+		for _, ring := range poly {
+			if len(ring) == 0 {
+				continue
+			}
+			outRing := make([][]float64, len(ring))
+			for k, pos := range ring {
+				outRing[k] = []float64{pos[0], pos[1]}
+			}
+			outPoly = append(outPoly, outRing)
+		}
+		if len(outPoly) > 0 {
+			out = append(out, outPoly)
+		}
+	}
+	return out
+}
+
+func TestWKTRoundTrip(t *testing.T) {
+	for name, geom := range loadFixtures(t) {
+		wkt, err := MarshalWKT(geom)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		parsed, err := ParseWKT(wkt)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(parsed, geom) {
+			t.Errorf("%s: round trip mismatch\n%s", name, wkt)
+		}
+	}
+}
+
+func TestParseWKT(t *testing.T) {
+	square := polygol.Geom{{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}}
+
+	testCases := []struct {
+		name string
+		wkt  string
+		geom polygol.Geom
+	}{
+		{"polygon", "POLYGON ((0 0, 1 0, 1 1, 0 1, 0 0))", square},
+		{"lowercase, no spaces", "polygon((0 0,1 0,1 1,0 1,0 0))", square},
+		{"z", "POLYGON Z ((0 0 5, 1 0 5, 1 1 5, 0 1 5, 0 0 5))", square},
+		{"zm", "POLYGON ZM ((0 0 5 6, 1 0 5 6, 1 1 5 6, 0 1 5 6, 0 0 5 6))", square},
+		{"ewkt", "SRID=4326;POLYGON ((0 0, 1 0, 1 1, 0 1, 0 0))", square},
+		{"multipolygon", "MULTIPOLYGON (((0 0, 1 0, 1 1, 0 1, 0 0)), EMPTY, ((2 2, 3 2, 3 3, 2 2)))",
+			polygol.Geom{square[0], {{{2, 2}, {3, 2}, {3, 3}, {2, 2}}}}},
+		{"with hole", "POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0), (1 1, 1 2, 2 2, 1 1))",
+			polygol.Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, {{1, 1}, {1, 2}, {2, 2}, {1, 1}}}}},
+		{"exponents", "POLYGON ((-1.5e3 0, 1E-3 0, 0 .5, -1.5e3 0))",
+			polygol.Geom{{{{-1500, 0}, {0.001, 0}, {0, 0.5}, {-1500, 0}}}}},
+		{"empty polygon", "POLYGON EMPTY", polygol.Geom{}},
+		{"empty multipolygon", "MULTIPOLYGON EMPTY", polygol.Geom{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			geom, err := ParseWKT(tc.wkt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(geom, tc.geom) {
+				t.Errorf("expected %v, got %v", tc.geom, geom)
+			}
+		})
+	}
+}
+
+func TestParseWKTErrors(t *testing.T) {
+	for _, wkt := range []string{
+		"",
+		"POINT (0 0)",
+		"POLYGON ((0 0, 1 0, 1 1, 0 0)",
+		"POLYGON ((0 0, 1 0, 1 x, 0 0))",
+		"POLYGON ((0 0, 1 0, 1 1, 0 0)) extra",
+		"POLYGON Z ((0 0, 1 0, 1 1, 0 0))",
+		"SRID=4326 POLYGON ((0 0, 1 0, 1 1, 0 0))",
+	} {
+		if _, err := ParseWKT(wkt); err == nil {
+			t.Errorf("expected an error parsing %q", wkt)
+		}
+	}
+}
+
+func TestMarshalWKT(t *testing.T) {
+	testCases := []struct {
+		geom polygol.Geom
+		wkt  string
+	}{
+		{polygol.Geom{}, "MULTIPOLYGON EMPTY"},
+		{polygol.Geom{{{{0, 0}, {1.5, 0}, {1, 1e-7}, {0, 0}}}}, "POLYGON ((0 0,1.5 0,1 0.0000001,0 0))"},
+		{polygol.Geom{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, {{{2, 2}, {3, 2}, {3, 3}, {2, 2}}}},
+			"MULTIPOLYGON (((0 0,1 0,1 1,0 0)),((2 2,3 2,3 3,2 2)))"},
+	}
+	for _, tc := range testCases {
+		wkt, err := MarshalWKT(tc.geom)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wkt != tc.wkt {
+			t.Errorf("expected %s, got %s", tc.wkt, wkt)
+		}
+	}
+
+	// a position without a y is an error rather than a panic
+	if wkt, err := MarshalWKT(polygol.Geom{{{{0, 0}, {1}, {1, 1}, {0, 0}}}}); err == nil {
+		t.Errorf("expected an error, got %s", wkt)
+	}
+}
@@ -0,0 +1,274 @@
+package geoio
+
+// dBASE III tables, which hold the attributes of the records of a Shapefile.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dbfHeaderLen     = 32
+	dbfFieldLen      = 32
+	dbfHeaderEnd     = 0x0d
+	dbfEOF           = 0x1a
+	dbfMaxNameLen    = 10
+	dbfDeletedRecord = '*'
+)
+
+type dbfField struct {
+	Field
+	dbfType byte
+}
+
+type dbfReader struct {
+	r         io.Reader
+	fields    []dbfField
+	recordLen int
+	remaining int
+}
+
+func newDBFReader(r io.Reader) (*dbfReader, error) {
+	header := make([]byte, dbfHeaderLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("dbf: reading header: %w", err)
+	}
+	d := &dbfReader{
+		r:         r,
+		remaining: int(binary.LittleEndian.Uint32(header[4:])),
+		recordLen: int(binary.LittleEndian.Uint16(header[10:])),
+	}
+	headerLen := int(binary.LittleEndian.Uint16(header[8:]))
+	if headerLen < dbfHeaderLen+1 {
+		return nil, fmt.Errorf("dbf: invalid header length %d", headerLen)
+	}
+
+	descriptors := make([]byte, headerLen-dbfHeaderLen)
+	if _, err := io.ReadFull(r, descriptors); err != nil {
+		return nil, fmt.Errorf("dbf: reading fields: %w", err)
+	}
+	width := 1 // deletion flag
+	for i := 0; i+dbfFieldLen <= len(descriptors) && descriptors[i] != dbfHeaderEnd; i += dbfFieldLen {
+		desc := descriptors[i : i+dbfFieldLen]
+		name := string(bytes.TrimRight(desc[:11], "\x00 "))
+		f := dbfField{
+			Field:   Field{Name: name, Length: int(desc[16]), Decimals: int(desc[17])},
+			dbfType: desc[11],
+		}
+		switch f.dbfType {
+		case 'N', 'F':
+			f.Type = Float
+			if f.dbfType == 'N' && f.Decimals == 0 {
+				f.Type = Int
+			}
+		case 'L':
+			f.Type = Bool
+		default:
+			// C, D and anything else read as text
+			f.Type = String
+		}
+		d.fields = append(d.fields, f)
+		width += f.Length
+	}
+	if width > d.recordLen {
+		return nil, fmt.Errorf("dbf: fields are %d bytes wide, records only %d", width, d.recordLen)
+	}
+	return d, nil
+}
+
+// next reads the attributes of the next record that isn't deleted.
+func (d *dbfReader) next() (map[string]any, error) {
+	record := make([]byte, d.recordLen)
+	for {
+		if d.remaining == 0 {
+			return nil, io.EOF
+		}
+		if _, err := io.ReadFull(d.r, record); err != nil {
+			return nil, fmt.Errorf("dbf: reading record: %w", err)
+		}
+		d.remaining--
+		if record[0] != dbfDeletedRecord {
+			break
+		}
+	}
+
+	props := make(map[string]any, len(d.fields))
+	pos := 1
+	for _, f := range d.fields {
+		raw := strings.TrimSpace(string(record[pos : pos+f.Length]))
+		pos += f.Length
+		props[f.Name] = parseDBFValue(f, raw)
+	}
+	return props, nil
+}
+
+func parseDBFValue(f dbfField, raw string) any {
+	switch f.Type {
+	case Int:
+		if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return i
+		}
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v
+		}
+		return nil
+	case Float:
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v
+		}
+		return nil
+	case Bool:
+		switch raw {
+		case "T", "t", "Y", "y":
+			return true
+		case "F", "f", "N", "n":
+			return false
+		}
+		return nil
+	}
+	return raw
+}
+
+type dbfWriter struct {
+	w         io.WriteSeeker
+	fields    []dbfField
+	recordLen int
+	count     int
+}
+
+func newDBFWriter(w io.WriteSeeker, fields []Field) (*dbfWriter, error) {
+	d := &dbfWriter{w: w, recordLen: 1}
+	for _, f := range fields {
+		if len(f.Name) == 0 || len(f.Name) > dbfMaxNameLen {
+			return nil, fmt.Errorf("dbf: field name %q must have 1 to %d characters", f.Name, dbfMaxNameLen)
+		}
+		df := dbfField{Field: f}
+		switch f.Type {
+		case String:
+			df.dbfType = 'C'
+			df.Length = defaultInt(f.Length, 254)
+			df.Decimals = 0
+		case Int:
+			df.dbfType = 'N'
+			df.Length = defaultInt(f.Length, 18)
+			df.Decimals = 0
+		case Float:
+			df.dbfType = 'F'
+			df.Length = defaultInt(f.Length, 24)
+			df.Decimals = defaultInt(f.Decimals, 15)
+		case Bool:
+			df.dbfType = 'L'
+			df.Length = 1
+			df.Decimals = 0
+		default:
+			return nil, fmt.Errorf("dbf: unknown type of field %q", f.Name)
+		}
+		if df.Length > 254 {
+			return nil, fmt.Errorf("dbf: field %q is longer than 254", f.Name)
+		}
+		d.fields = append(d.fields, df)
+		d.recordLen += df.Length
+	}
+	return d, d.writeHeader()
+}
+
+func defaultInt(v, def int) int {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+func (d *dbfWriter) writeHeader() error {
+	headerLen := dbfHeaderLen + dbfFieldLen*len(d.fields) + 1
+	header := make([]byte, headerLen)
+	header[0] = 0x03 // dBASE III without memo
+	now := time.Now()
+	header[1], header[2], header[3] = byte(now.Year()-1900), byte(now.Month()), byte(now.Day())
+	binary.LittleEndian.PutUint32(header[4:], uint32(d.count))
+	binary.LittleEndian.PutUint16(header[8:], uint16(headerLen))
+	binary.LittleEndian.PutUint16(header[10:], uint16(d.recordLen))
+	for i, f := range d.fields {
+		desc := header[dbfHeaderLen+dbfFieldLen*i:]
+		copy(desc[:dbfMaxNameLen], f.Name)
+		desc[11] = f.dbfType
+		desc[16] = byte(f.Length)
+		desc[17] = byte(f.Decimals)
+	}
+	header[headerLen-1] = dbfHeaderEnd
+	_, err := d.w.Write(header)
+	return err
+}
+
+func (d *dbfWriter) write(props map[string]any) error {
+	record := make([]byte, 1, d.recordLen)
+	record[0] = ' '
+	for _, f := range d.fields {
+		value, err := formatDBFValue(f, props[f.Name])
+		if err != nil {
+			return err
+		}
+		if len(value) > f.Length {
+			return fmt.Errorf("dbf: value %q is longer than field %q", value, f.Name)
+		}
+		pad := strings.Repeat(" ", f.Length-len(value))
+		if f.Type == String {
+			record = append(record, value+pad...)
+		} else {
+			record = append(record, pad+value...)
+		}
+	}
+	d.count++
+	_, err := d.w.Write(record)
+	return err
+}
+
+func formatDBFValue(f dbfField, v any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	switch f.Type {
+	case String:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprint(v), nil
+	case Int:
+		if i, ok := toInt64(v); ok {
+			return strconv.FormatInt(i, 10), nil
+		}
+	case Float:
+		if x, ok := toFloat64(v); ok {
+			s := strconv.FormatFloat(x, 'f', f.Decimals, 64)
+			if len(s) > f.Length {
+				// too wide with all of the decimals, use the shortest form
+				s = strconv.FormatFloat(x, 'g', -1, 64)
+			}
+			return s, nil
+		}
+	case Bool:
+		if b, ok := v.(bool); ok {
+			if b {
+				return "T", nil
+			}
+			return "F", nil
+		}
+	}
+	return "", fieldValueError(f.Field, v)
+}
+
+// close ends the table and fills in the number of records.
+func (d *dbfWriter) close() error {
+	if _, err := d.w.Write([]byte{dbfEOF}); err != nil {
+		return err
+	}
+	if _, err := d.w.Seek(4, io.SeekStart); err != nil {
+		return err
+	}
+	return binary.Write(d.w, binary.LittleEndian, uint32(d.count))
+}
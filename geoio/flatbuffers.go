@@ -0,0 +1,278 @@
+package geoio
+
+// Just enough of FlatBuffers to read and write the FlatGeobuf header and
+// features: tables of scalars, strings, vectors and other tables.
+//
+// The builder writes front to back, each table right after its vtable and
+// before the objects it refers to, as references only ever point forward.
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// fbTable is a table at pos in a FlatBuffers buffer. Reading out of the
+// bounds of the buffer panics, which fbDecode turns into an error.
+type fbTable struct {
+	buf []byte
+	pos int
+}
+
+func fbRoot(buf []byte) fbTable {
+	return fbTable{buf: buf, pos: int(binary.LittleEndian.Uint32(buf))}
+}
+
+// field returns the position of field id of the table, or 0 if it's absent.
+func (t fbTable) field(id int) int {
+	vtable := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	vtableLen := int(binary.LittleEndian.Uint16(t.buf[vtable:]))
+	entry := 4 + 2*id
+	if entry+2 > vtableLen {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(t.buf[vtable+entry:]))
+	if off == 0 {
+		return 0
+	}
+	return t.pos + off
+}
+
+func (t fbTable) uint8(id int, def uint8) uint8 {
+	if pos := t.field(id); pos != 0 {
+		return t.buf[pos]
+	}
+	return def
+}
+
+func (t fbTable) uint16(id int, def uint16) uint16 {
+	if pos := t.field(id); pos != 0 {
+		return binary.LittleEndian.Uint16(t.buf[pos:])
+	}
+	return def
+}
+
+func (t fbTable) uint64(id int, def uint64) uint64 {
+	if pos := t.field(id); pos != 0 {
+		return binary.LittleEndian.Uint64(t.buf[pos:])
+	}
+	return def
+}
+
+// deref follows the reference at pos.
+func (t fbTable) deref(pos int) int {
+	return pos + int(binary.LittleEndian.Uint32(t.buf[pos:]))
+}
+
+func (t fbTable) string(id int) string {
+	pos := t.field(id)
+	if pos == 0 {
+		return ""
+	}
+	pos = t.deref(pos)
+	n := int(binary.LittleEndian.Uint32(t.buf[pos:]))
+	return string(t.buf[pos+4 : pos+4+n])
+}
+
+// vector returns the position of the first element of vector field id and
+// its length.
+func (t fbTable) vector(id int) (int, int) {
+	pos := t.field(id)
+	if pos == 0 {
+		return 0, 0
+	}
+	pos = t.deref(pos)
+	return pos + 4, int(binary.LittleEndian.Uint32(t.buf[pos:]))
+}
+
+func (t fbTable) bytes(id int) []byte {
+	pos, n := t.vector(id)
+	return t.buf[pos : pos+n]
+}
+
+func (t fbTable) table(id int) (fbTable, bool) {
+	pos := t.field(id)
+	if pos == 0 {
+		return fbTable{}, false
+	}
+	return fbTable{buf: t.buf, pos: t.deref(pos)}, true
+}
+
+// tables returns the tables of vector field id.
+func (t fbTable) tables(id int) []fbTable {
+	pos, n := t.vector(id)
+	tables := make([]fbTable, n)
+	for i := range tables {
+		tables[i] = fbTable{buf: t.buf, pos: t.deref(pos + 4*i)}
+	}
+	return tables
+}
+
+// fbValue is a field of a table being built: either an inline scalar, or an
+// object written after the table that the field refers to.
+type fbValue struct {
+	scalar []byte
+	object func(b *fbBuilder) int
+}
+
+func fbUint8(v uint8) *fbValue { return &fbValue{scalar: []byte{v}} }
+
+func fbBool(v bool) *fbValue {
+	if v {
+		return fbUint8(1)
+	}
+	return fbUint8(0)
+}
+
+func fbUint16(v uint16) *fbValue {
+	return &fbValue{scalar: appendUint16(nil, v)}
+}
+
+func fbUint64(v uint64) *fbValue {
+	return &fbValue{scalar: appendUint64(nil, v)}
+}
+
+func fbString(s string) *fbValue {
+	return &fbValue{object: func(b *fbBuilder) int {
+		b.align(4, 0)
+		pos := len(b.buf)
+		b.buf = appendUint32(b.buf, uint32(len(s)))
+		b.buf = append(b.buf, s...)
+		b.buf = append(b.buf, 0)
+		return pos
+	}}
+}
+
+// fbScalars is a vector of n scalars of size bytes each, already encoded.
+func fbScalars(data []byte, n, size int) *fbValue {
+	return &fbValue{object: func(b *fbBuilder) int {
+		b.align(size, 4)
+		pos := len(b.buf)
+		b.buf = appendUint32(b.buf, uint32(n))
+		b.buf = append(b.buf, data...)
+		return pos
+	}}
+}
+
+func fbFloat64s(values []float64) *fbValue {
+	data := make([]byte, 0, 8*len(values))
+	for _, v := range values {
+		data = appendUint64(data, math.Float64bits(v))
+	}
+	return fbScalars(data, len(values), 8)
+}
+
+func fbUint32s(values []uint32) *fbValue {
+	data := make([]byte, 0, 4*len(values))
+	for _, v := range values {
+		data = appendUint32(data, v)
+	}
+	return fbScalars(data, len(values), 4)
+}
+
+func fbTableValue(fields []*fbValue) *fbValue {
+	return &fbValue{object: func(b *fbBuilder) int {
+		return b.table(fields)
+	}}
+}
+
+func fbTables(tables [][]*fbValue) *fbValue {
+	return &fbValue{object: func(b *fbBuilder) int {
+		b.align(4, 0)
+		pos := len(b.buf)
+		b.buf = appendUint32(b.buf, uint32(len(tables)))
+		refs := len(b.buf)
+		b.buf = append(b.buf, make([]byte, 4*len(tables))...)
+		for i, fields := range tables {
+			b.patch(refs+4*i, b.table(fields))
+		}
+		return pos
+	}}
+}
+
+type fbBuilder struct {
+	buf []byte
+}
+
+// fbBuild returns the buffer of a root table with the given fields, nil
+// fields being absent.
+func fbBuild(fields []*fbValue) []byte {
+	b := &fbBuilder{buf: make([]byte, 4)}
+	b.patch(0, b.table(fields))
+	return b.buf
+}
+
+// align pads the buffer so that extra bytes from now it's aligned to size.
+func (b *fbBuilder) align(size, extra int) {
+	for (len(b.buf)+extra)%size != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// patch points the reference at pos to target.
+func (b *fbBuilder) patch(pos, target int) {
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(target-pos))
+}
+
+func (b *fbBuilder) table(fields []*fbValue) int {
+	// lay the fields out widest first so they all fall on their alignment,
+	// references being 4 bytes
+	offsets := make([]int, len(fields))
+	size := 4 // soffset to the vtable
+	for _, width := range []int{8, 4, 2, 1} {
+		for i, f := range fields {
+			if f == nil || fieldWidth(f) != width {
+				continue
+			}
+			for size%width != 0 {
+				size++
+			}
+			offsets[i] = size
+			size += width
+		}
+	}
+
+	b.align(2, 0)
+	vtable := len(b.buf)
+	b.buf = appendUint16(b.buf, uint16(4+2*len(fields)))
+	b.buf = appendUint16(b.buf, uint16(size))
+	for _, off := range offsets {
+		b.buf = appendUint16(b.buf, uint16(off))
+	}
+
+	b.align(8, 0)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(int32(pos-vtable)))
+	for i, f := range fields {
+		if f != nil && f.object == nil {
+			copy(b.buf[pos+offsets[i]:], f.scalar)
+		}
+	}
+	for i, f := range fields {
+		if f != nil && f.object != nil {
+			b.patch(pos+offsets[i], f.object(b))
+		}
+	}
+	return pos
+}
+
+func fieldWidth(f *fbValue) int {
+	if f.object != nil {
+		return 4
+	}
+	return len(f.scalar)
+}
+
+// The little endian appenders of encoding/binary are newer than go 1.18.
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v), byte(v>>8))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(v)), uint32(v>>32))
+}
@@ -0,0 +1,521 @@
+package geoio
+
+// FlatGeobuf files: a magic number, a size prefixed header, an optional
+// spatial index and then size prefixed features, all of them FlatBuffers.
+// See https://flatgeobuf.org for the schemas the field ids below come from.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/engelsjk/polygol"
+)
+
+var fgbMagic = []byte("fgb\x03fgb\x00")
+
+const (
+	fgbIndexNodeLen = 40 // box of four doubles and an offset
+	fgbMaxBufLen    = 1 << 30
+)
+
+// Geometry types.
+const (
+	fgbUnknown      = 0
+	fgbPolygon      = 3
+	fgbMultiPolygon = 6
+)
+
+// Column types.
+const (
+	fgbByte     = 0
+	fgbUByte    = 1
+	fgbBool     = 2
+	fgbShort    = 3
+	fgbUShort   = 4
+	fgbInt      = 5
+	fgbUInt     = 6
+	fgbLong     = 7
+	fgbULong    = 8
+	fgbFloat    = 9
+	fgbDouble   = 10
+	fgbString   = 11
+	fgbJSON     = 12
+	fgbDateTime = 13
+	fgbBinary   = 14
+)
+
+// Field ids of the Header, Column, Feature and Geometry tables.
+const (
+	fgbHeaderGeometryType  = 2
+	fgbHeaderColumns       = 7
+	fgbHeaderFeaturesCount = 8
+	fgbHeaderIndexNodeSize = 9
+	fgbHeaderFields        = 14
+
+	fgbColumnName   = 0
+	fgbColumnType   = 1
+	fgbColumnFields = 11
+
+	fgbFeatureGeometry   = 0
+	fgbFeatureProperties = 1
+
+	fgbGeometryEnds   = 0
+	fgbGeometryXY     = 1
+	fgbGeometryType   = 6
+	fgbGeometryParts  = 7
+	fgbGeometryFields = 8
+)
+
+type fgbColumn struct {
+	Field
+	fgbType uint8
+}
+
+// FlatGeobufReader reads the polygon records of a FlatGeobuf file.
+type FlatGeobufReader struct {
+	r            *bufio.Reader
+	columns      []fgbColumn
+	geometryType uint8
+	closer       io.Closer
+}
+
+// OpenFlatGeobuf opens the FlatGeobuf file at path.
+func OpenFlatGeobuf(path string) (*FlatGeobufReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewFlatGeobufReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
+
+// NewFlatGeobufReader reads the header of a FlatGeobuf file and skips its
+// spatial index, leaving the records to Next.
+func NewFlatGeobufReader(r io.Reader) (*FlatGeobufReader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(fgbMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("fgb: reading magic number: %w", err)
+	}
+	// the last byte is the patch version
+	if !bytes.Equal(magic[:7], fgbMagic[:7]) {
+		return nil, errors.New("fgb: not a FlatGeobuf file of version 3")
+	}
+
+	buf, err := readSizePrefixed(br)
+	if err != nil {
+		return nil, fmt.Errorf("fgb: reading header: %w", err)
+	}
+	fr := &FlatGeobufReader{r: br}
+	var featuresCount uint64
+	var indexNodeSize uint16
+	err = fbDecode(func() error {
+		header := fbRoot(buf)
+		fr.geometryType = header.uint8(fgbHeaderGeometryType, fgbUnknown)
+		featuresCount = header.uint64(fgbHeaderFeaturesCount, 0)
+		indexNodeSize = header.uint16(fgbHeaderIndexNodeSize, 16)
+		for _, column := range header.tables(fgbHeaderColumns) {
+			c := fgbColumn{
+				Field:   Field{Name: column.string(fgbColumnName)},
+				fgbType: column.uint8(fgbColumnType, fgbByte),
+			}
+			switch c.fgbType {
+			case fgbBool:
+				c.Type = Bool
+			case fgbByte, fgbUByte, fgbShort, fgbUShort, fgbInt, fgbUInt, fgbLong, fgbULong:
+				c.Type = Int
+			case fgbFloat, fgbDouble:
+				c.Type = Float
+			case fgbString, fgbJSON, fgbDateTime, fgbBinary:
+				c.Type = String
+			default:
+				return fmt.Errorf("unknown type %d of column %q", c.fgbType, c.Name)
+			}
+			fr.columns = append(fr.columns, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fgb: header: %w", err)
+	}
+	switch fr.geometryType {
+	case fgbUnknown, fgbPolygon, fgbMultiPolygon:
+	default:
+		return nil, fmt.Errorf("fgb: geometry type %d isn't a polygon type", fr.geometryType)
+	}
+
+	if indexNodeSize > 0 && featuresCount > 0 {
+		if _, err := br.Discard(fgbIndexLen(featuresCount, indexNodeSize)); err != nil {
+			return nil, fmt.Errorf("fgb: skipping index: %w", err)
+		}
+	}
+	return fr, nil
+}
+
+// fgbIndexLen is the length in bytes of the packed Hilbert R-tree of a file.
+func fgbIndexLen(featuresCount uint64, nodeSize uint16) int {
+	size := uint64(nodeSize)
+	if size < 2 {
+		size = 2
+	}
+	n := featuresCount
+	numNodes := n
+	for {
+		n = (n + size - 1) / size
+		numNodes += n
+		if n == 1 {
+			break
+		}
+	}
+	return int(numNodes * fgbIndexNodeLen)
+}
+
+func readSizePrefixed(r io.Reader) ([]byte, error) {
+	prefix := make([]byte, 4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint32(prefix)
+	if n < 4 || n > fgbMaxBufLen {
+		return nil, fmt.Errorf("invalid length %d", n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// fbDecode runs decode, turning reads out of the bounds of a corrupt buffer
+// into an error.
+func fbDecode(decode func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(error); !ok {
+				panic(r)
+			}
+			err = errors.New("corrupt FlatBuffers data")
+		}
+	}()
+	return decode()
+}
+
+// Fields returns the columns of the file.
+func (r *FlatGeobufReader) Fields() []Field {
+	fields := make([]Field, len(r.columns))
+	for i, c := range r.columns {
+		fields[i] = c.Field
+	}
+	return fields
+}
+
+// Next reads the next record. Features without a geometry read as empty
+// geometries.
+func (r *FlatGeobufReader) Next() (polygol.Geom, map[string]any, error) {
+	if _, err := r.r.Peek(1); errors.Is(err, io.EOF) {
+		return nil, nil, io.EOF
+	}
+	buf, err := readSizePrefixed(r.r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fgb: reading feature: %w", err)
+	}
+
+	var geom polygol.Geom
+	var props map[string]any
+	err = fbDecode(func() error {
+		feature := fbRoot(buf)
+		geom = polygol.Geom{}
+		if g, ok := feature.table(fgbFeatureGeometry); ok {
+			var err error
+			if geom, err = fgbGeom(g, r.geometryType); err != nil {
+				return err
+			}
+		}
+		var err error
+		props, err = r.properties(feature.bytes(fgbFeatureProperties))
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("fgb: feature: %w", err)
+	}
+	return geom, props, nil
+}
+
+// fgbGeom reads a Polygon or MultiPolygon geometry table, whose own type
+// falls back on the type of the header.
+func fgbGeom(g fbTable, headerType uint8) (polygol.Geom, error) {
+	switch geometryType := g.uint8(fgbGeometryType, headerType); geometryType {
+	case fgbPolygon:
+		return polygol.Geom{fgbPolygonRings(g)}, nil
+	case fgbMultiPolygon:
+		geom := polygol.Geom{}
+		for _, part := range g.tables(fgbGeometryParts) {
+			geom = append(geom, fgbPolygonRings(part))
+		}
+		return geom, nil
+	default:
+		return nil, fmt.Errorf("geometry type %d isn't a polygon type", geometryType)
+	}
+}
+
+func fgbPolygonRings(g fbTable) [][][]float64 {
+	xyPos, xyLen := g.vector(fgbGeometryXY)
+	numPoints := xyLen / 2
+	point := func(i int) []float64 {
+		x := math.Float64frombits(binary.LittleEndian.Uint64(g.buf[xyPos+16*i:]))
+		y := math.Float64frombits(binary.LittleEndian.Uint64(g.buf[xyPos+16*i+8:]))
+		return []float64{x, y}
+	}
+
+	// ends are the end indices of the rings, and only needed with holes
+	ends := []int{numPoints}
+	if endsPos, endsLen := g.vector(fgbGeometryEnds); endsLen > 0 {
+		ends = make([]int, endsLen)
+		for i := range ends {
+			ends[i] = int(binary.LittleEndian.Uint32(g.buf[endsPos+4*i:]))
+		}
+	}
+
+	poly := [][][]float64{}
+	start := 0
+	for _, end := range ends {
+		if end > numPoints {
+			end = numPoints
+		}
+		ring := [][]float64{}
+		for i := start; i < end; i++ {
+			ring = append(ring, point(i))
+		}
+		if len(ring) > 0 {
+			poly = append(poly, ring)
+		}
+		start = end
+	}
+	return poly
+}
+
+func (r *FlatGeobufReader) properties(data []byte) (map[string]any, error) {
+	props := map[string]any{}
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, errors.New("truncated properties")
+		}
+		i := int(binary.LittleEndian.Uint16(data))
+		data = data[2:]
+		if i >= len(r.columns) {
+			return nil, fmt.Errorf("property of column %d of %d", i, len(r.columns))
+		}
+		c := r.columns[i]
+		var n int
+		var v any
+		switch c.fgbType {
+		case fgbByte:
+			n, v = 1, int64(int8(data[0]))
+		case fgbUByte:
+			n, v = 1, int64(data[0])
+		case fgbBool:
+			n, v = 1, data[0] != 0
+		case fgbShort:
+			n, v = 2, int64(int16(binary.LittleEndian.Uint16(data)))
+		case fgbUShort:
+			n, v = 2, int64(binary.LittleEndian.Uint16(data))
+		case fgbInt:
+			n, v = 4, int64(int32(binary.LittleEndian.Uint32(data)))
+		case fgbUInt:
+			n, v = 4, int64(binary.LittleEndian.Uint32(data))
+		case fgbLong, fgbULong:
+			n, v = 8, int64(binary.LittleEndian.Uint64(data))
+		case fgbFloat:
+			n, v = 4, float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+		case fgbDouble:
+			n, v = 8, math.Float64frombits(binary.LittleEndian.Uint64(data))
+		default:
+			length := int(binary.LittleEndian.Uint32(data))
+			n, v = 4+length, string(data[4:4+length])
+		}
+		props[c.Name] = v
+		data = data[n:]
+	}
+	return props, nil
+}
+
+// Close closes the file opened by OpenFlatGeobuf.
+func (r *FlatGeobufReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// FlatGeobufWriter writes polygon records to a FlatGeobuf file, as
+// MultiPolygons and without a spatial index, so that they can be streamed.
+type FlatGeobufWriter struct {
+	w       *bufio.Writer
+	columns []fgbColumn
+	closer  io.Closer
+}
+
+// CreateFlatGeobuf creates the FlatGeobuf file at path, with the given
+// attribute fields.
+func CreateFlatGeobuf(path string, fields []Field) (*FlatGeobufWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewFlatGeobufWriter(f, fields)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.closer = f
+	return w, nil
+}
+
+// NewFlatGeobufWriter writes the header of a FlatGeobuf file with the given
+// attribute fields to w, leaving the records to Write.
+func NewFlatGeobufWriter(w io.Writer, fields []Field) (*FlatGeobufWriter, error) {
+	fw := &FlatGeobufWriter{w: bufio.NewWriter(w)}
+	columns := [][]*fbValue{}
+	for _, f := range fields {
+		c := fgbColumn{Field: f}
+		switch f.Type {
+		case String:
+			c.fgbType = fgbString
+		case Int:
+			c.fgbType = fgbLong
+		case Float:
+			c.fgbType = fgbDouble
+		case Bool:
+			c.fgbType = fgbBool
+		default:
+			return nil, fmt.Errorf("fgb: unknown type of field %q", f.Name)
+		}
+		fw.columns = append(fw.columns, c)
+		column := make([]*fbValue, fgbColumnFields)
+		column[fgbColumnName] = fbString(f.Name)
+		column[fgbColumnType] = fbUint8(c.fgbType)
+		columns = append(columns, column)
+	}
+
+	header := make([]*fbValue, fgbHeaderFields)
+	header[fgbHeaderGeometryType] = fbUint8(fgbMultiPolygon)
+	header[fgbHeaderColumns] = fbTables(columns)
+	// the number of features is unknown up front, and without it there can
+	// be no index
+	header[fgbHeaderFeaturesCount] = fbUint64(0)
+	header[fgbHeaderIndexNodeSize] = fbUint16(0)
+
+	if _, err := fw.w.Write(fgbMagic); err != nil {
+		return nil, err
+	}
+	if err := fw.writeSizePrefixed(fbBuild(header)); err != nil {
+		return nil, err
+	}
+	return fw, nil
+}
+
+func (w *FlatGeobufWriter) writeSizePrefixed(buf []byte) error {
+	if _, err := w.w.Write(appendUint32(nil, uint32(len(buf)))); err != nil {
+		return err
+	}
+	_, err := w.w.Write(buf)
+	return err
+}
+
+// Write writes a record, an empty geometry as a feature without one.
+func (w *FlatGeobufWriter) Write(geom polygol.Geom, properties map[string]any) error {
+	props := []byte{}
+	for i, c := range w.columns {
+		v, ok := properties[c.Name]
+		if !ok || v == nil {
+			continue
+		}
+		props = appendUint16(props, uint16(i))
+		switch c.Type {
+		case String:
+			s, ok := v.(string)
+			if !ok {
+				s = fmt.Sprint(v)
+			}
+			props = appendUint32(props, uint32(len(s)))
+			props = append(props, s...)
+		case Int:
+			n, ok := toInt64(v)
+			if !ok {
+				return fmt.Errorf("fgb: %w", fieldValueError(c.Field, v))
+			}
+			props = appendUint64(props, uint64(n))
+		case Float:
+			x, ok := toFloat64(v)
+			if !ok {
+				return fmt.Errorf("fgb: %w", fieldValueError(c.Field, v))
+			}
+			props = appendUint64(props, math.Float64bits(x))
+		case Bool:
+			b, ok := v.(bool)
+			if !ok {
+				return fmt.Errorf("fgb: %w", fieldValueError(c.Field, v))
+			}
+			if b {
+				props = append(props, 1)
+			} else {
+				props = append(props, 0)
+			}
+		}
+	}
+
+	feature := make([]*fbValue, 2)
+	if len(geom) > 0 {
+		feature[fgbFeatureGeometry] = fbTableValue(fgbMultiPolygonTable(geom))
+	}
+	feature[fgbFeatureProperties] = fbScalars(props, len(props), 1)
+	return w.writeSizePrefixed(fbBuild(feature))
+}
+
+func fgbMultiPolygonTable(geom polygol.Geom) []*fbValue {
+	parts := make([][]*fbValue, len(geom))
+	for i, poly := range geom {
+		ends := []uint32{}
+		xy := []float64{}
+		for _, ring := range poly {
+			for _, pt := range ring {
+				xy = append(xy, pt[0], pt[1])
+			}
+			ends = append(ends, uint32(len(xy)/2))
+		}
+		part := make([]*fbValue, fgbGeometryFields)
+		if len(ends) > 1 {
+			part[fgbGeometryEnds] = fbUint32s(ends)
+		}
+		part[fgbGeometryXY] = fbFloat64s(xy)
+		part[fgbGeometryType] = fbUint8(fgbPolygon)
+		parts[i] = part
+	}
+	g := make([]*fbValue, fgbGeometryFields)
+	g[fgbGeometryType] = fbUint8(fgbMultiPolygon)
+	g[fgbGeometryParts] = fbTables(parts)
+	return g
+}
+
+// Close flushes the records, and closes the file created by
+// CreateFlatGeobuf.
+func (w *FlatGeobufWriter) Close() error {
+	err := w.w.Flush()
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,234 @@
+package geoio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/engelsjk/polygol"
+)
+
+func writeTestFlatGeobuf(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w, err := NewFlatGeobufWriter(&buf, testFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range testRecords {
+		if err := w.Write(r.geom, r.props); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFlatGeobufRoundTrip(t *testing.T) {
+	r, err := NewFlatGeobufReader(bytes.NewReader(writeTestFlatGeobuf(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := r.Fields(); !reflect.DeepEqual(fields, testFields) {
+		t.Errorf("expected fields %v, got %v", testFields, fields)
+	}
+
+	geoms, props, err := ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(geoms) != len(testRecords) {
+		t.Fatalf("expected %d records, got %d", len(testRecords), len(geoms))
+	}
+	for i, r := range testRecords {
+		if !reflect.DeepEqual(geoms[i], r.geom) {
+			t.Errorf("record %d: expected %v, got %v", i, r.geom, geoms[i])
+		}
+		// null properties aren't written at all
+		expected := map[string]any{}
+		for k, v := range r.props {
+			if v != nil {
+				expected[k] = v
+			}
+		}
+		if !reflect.DeepEqual(props[i], expected) {
+			t.Errorf("record %d: expected properties %v, got %v", i, expected, props[i])
+		}
+	}
+}
+
+// The fixtures in testdata are written by testdata/fgbgen with the reference
+// FlatBuffers builder, not with the writer above.
+
+func TestFlatGeobufFixture(t *testing.T) {
+	r, err := OpenFlatGeobuf("testdata/polygons.fgb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	fields := []Field{
+		{Name: "name", Type: String},
+		{Name: "population", Type: Int},
+		{Name: "area", Type: Float},
+		{Name: "coastal", Type: Bool},
+		{Name: "rank", Type: Int},
+		{Name: "density", Type: Float},
+		{Name: "code", Type: Int},
+		{Name: "updated", Type: String},
+	}
+	if f := r.Fields(); !reflect.DeepEqual(f, fields) {
+		t.Errorf("expected fields %v, got %v", fields, f)
+	}
+
+	// the features come after an index of 3 leaves and a root
+	geoms, props, err := ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedGeoms := []polygol.Geom{
+		{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, {{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}}}},
+		{{{{10, 0}, {12, 0}, {11, 2}, {10, 0}}}},
+		{{{{-5, -5}, {-4, -5}, {-4, -4}, {-5, -4}, {-5, -5}}}},
+	}
+	expectedProps := []map[string]any{
+		{
+			"name": "square", "population": int64(5000000000), "area": 12.5, "coastal": true,
+			"rank": int64(-3), "density": 2.5, "code": int64(42), "updated": "2020-01-02T03:04:05Z",
+		},
+		{"name": "triangle", "coastal": false},
+		{},
+	}
+	if !reflect.DeepEqual(geoms, expectedGeoms) {
+		t.Errorf("expected %v, got %v", expectedGeoms, geoms)
+	}
+	if !reflect.DeepEqual(props, expectedProps) {
+		t.Errorf("expected properties %v, got %v", expectedProps, props)
+	}
+}
+
+func TestFlatGeobufFixtureMixed(t *testing.T) {
+	// an unknown geometry type in the header, the features having their own,
+	// and no index
+	r, err := OpenFlatGeobuf("testdata/mixed.fgb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	geoms, props, err := ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedGeoms := []polygol.Geom{
+		{
+			{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}},
+			{
+				{{2, 2}, {3, 2}, {3, 3}, {2, 3}, {2, 2}},
+				{{2.25, 2.25}, {2.25, 2.75}, {2.75, 2.75}, {2.75, 2.25}, {2.25, 2.25}},
+			},
+		},
+		{{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}},
+		{},
+	}
+	if !reflect.DeepEqual(geoms, expectedGeoms) {
+		t.Errorf("expected %v, got %v", expectedGeoms, geoms)
+	}
+	for i, name := range []string{"two squares", "triangle", "no geometry"} {
+		if props[i]["name"] != name {
+			t.Errorf("record %d: expected name %q, got %v", i, name, props[i])
+		}
+	}
+}
+
+func TestFlatGeobufIndex(t *testing.T) {
+	// a header counting 3 features with an index of nodes of 2, which makes
+	// 3 + 2 + 1 nodes to skip
+	header := make([]*fbValue, fgbHeaderFields)
+	header[fgbHeaderGeometryType] = fbUint8(fgbPolygon)
+	header[fgbHeaderFeaturesCount] = fbUint64(3)
+	header[fgbHeaderIndexNodeSize] = fbUint16(2)
+	if n := fgbIndexLen(3, 2); n != 6*fgbIndexNodeLen {
+		t.Errorf("expected an index of 6 nodes, got %d bytes", n)
+	}
+
+	feature := make([]*fbValue, 2)
+	geometry := make([]*fbValue, fgbGeometryFields)
+	geometry[fgbGeometryXY] = fbFloat64s([]float64{0, 0, 1, 0, 0, 1, 0, 0})
+	feature[fgbFeatureGeometry] = fbTableValue(geometry)
+
+	var buf bytes.Buffer
+	buf.Write(fgbMagic)
+	headerBuf := fbBuild(header)
+	buf.Write(appendUint32(nil, uint32(len(headerBuf))))
+	buf.Write(headerBuf)
+	buf.Write(make([]byte, fgbIndexLen(3, 2)))
+	featureBuf := fbBuild(feature)
+	buf.Write(appendUint32(nil, uint32(len(featureBuf))))
+	buf.Write(featureBuf)
+
+	r, err := NewFlatGeobufReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	geom, props, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := polygol.Geom{{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}}
+	if !reflect.DeepEqual(geom, expected) || len(props) != 0 {
+		t.Errorf("expected %v without properties, got %v %v", expected, geom, props)
+	}
+	if _, _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestFlatGeobufErrors(t *testing.T) {
+	data := writeTestFlatGeobuf(t)
+
+	notFgb := append([]byte{}, data...)
+	notFgb[3] = 2
+	if _, err := NewFlatGeobufReader(bytes.NewReader(notFgb)); err == nil {
+		t.Errorf("expected an error for version 2")
+	}
+
+	r, err := NewFlatGeobufReader(bytes.NewReader(data[:len(data)-10]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for err == nil {
+		_, _, err = r.Next()
+	}
+	if errors.Is(err, io.EOF) {
+		t.Errorf("expected an error for a truncated feature, got EOF")
+	}
+
+	// a feature whose vtable points out of the buffer
+	corrupt := append([]byte{}, data...)
+	for i := len(corrupt) - 1; i >= len(corrupt)-4; i-- {
+		corrupt[i] = 0xff
+	}
+	r, err = NewFlatGeobufReader(bytes.NewReader(corrupt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for err == nil {
+		_, _, err = r.Next()
+	}
+	if errors.Is(err, io.EOF) {
+		t.Errorf("expected an error for a corrupt feature, got EOF")
+	}
+
+	var buf bytes.Buffer
+	w, err := NewFlatGeobufWriter(&buf, testFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(polygol.Geom{}, map[string]any{"ok": "yes"}); err == nil {
+		t.Errorf("expected an error for a string in a bool field")
+	}
+}
@@ -0,0 +1,183 @@
+// Package geoio streams polygon records in and out of Shapefiles and
+// FlatGeobuf files, as polygol geometries along with their attributes.
+//
+// Readers return one record at a time from Next, and io.EOF after the last
+// one, so that large files never have to be held in memory at once. Writers
+// take the attribute fields up front, and then one record at a time.
+package geoio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/engelsjk/polygol"
+)
+
+// FieldType is the type of an attribute field.
+type FieldType int
+
+const (
+	// String fields read and write Go strings.
+	String FieldType = iota
+	// Int fields read as int64 and write any Go integer.
+	Int
+	// Float fields read as float64 and write any Go number.
+	Float
+	// Bool fields read and write Go bools.
+	Bool
+)
+
+func (t FieldType) String() string {
+	switch t {
+	case String:
+		return "string"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case Bool:
+		return "bool"
+	}
+	return fmt.Sprintf("FieldType(%d)", int(t))
+}
+
+// Field describes an attribute of the records of a file. Length and Decimals
+// only matter to the fixed width fields of Shapefiles, where they default to
+// sensible widths for the type.
+type Field struct {
+	Name     string
+	Type     FieldType
+	Length   int
+	Decimals int
+}
+
+// A Reader reads records one at a time, returning io.EOF after the last one.
+// Attributes missing from a record are absent or nil.
+type Reader interface {
+	Fields() []Field
+	Next() (polygol.Geom, map[string]any, error)
+}
+
+// A Writer writes records one at a time. Attributes without a field are
+// ignored and missing ones are written as empty.
+type Writer interface {
+	Write(geom polygol.Geom, properties map[string]any) error
+	Close() error
+}
+
+// ReadAll reads all remaining records, e.g. to feed polygol.Union or
+// polygol.DissolveByKey.
+func ReadAll(r Reader) ([]polygol.Geom, []map[string]any, error) {
+	geoms := []polygol.Geom{}
+	properties := []map[string]any{}
+	for {
+		geom, props, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return geoms, properties, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		geoms = append(geoms, geom)
+		properties = append(properties, props)
+	}
+}
+
+// toInt64 converts the Go number types to an int64 for an Int field.
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint:
+		return int64(n), true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		return int64(n), true
+	case float64:
+		if n == math.Trunc(n) {
+			return int64(n), true
+		}
+	case float32:
+		if float64(n) == math.Trunc(float64(n)) {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+// toFloat64 converts the Go number types to a float64 for a Float field.
+func toFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+	if i, ok := toInt64(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
+func fieldValueError(f Field, v any) error {
+	return fmt.Errorf("value %v of type %T doesn't fit %s field %q", v, v, f.Type, f.Name)
+}
+
+// ringSignedArea is the signed area of a ring, positive if counter-clockwise.
+func ringSignedArea(ring [][]float64) float64 {
+	area := 0.0
+	for i := 0; i < len(ring); i++ {
+		a := ring[i]
+		b := ring[(i+1)%len(ring)]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+	return area / 2
+}
+
+// ringContains tells whether a point is inside a ring, by ray casting.
+func ringContains(ring [][]float64, x, y float64) bool {
+	in := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi, xj, yj := ring[i][0], ring[i][1], ring[j][0], ring[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			in = !in
+		}
+	}
+	return in
+}
+
+// geomBbox returns minX, minY, maxX, maxY of the positions of geometries,
+// and false if there are none.
+func geomBbox(geoms ...polygol.Geom) ([4]float64, bool) {
+	bbox := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	found := false
+	for _, geom := range geoms {
+		for _, poly := range geom {
+			for _, ring := range poly {
+				for _, pos := range ring {
+					bbox[0] = math.Min(bbox[0], pos[0])
+					bbox[1] = math.Min(bbox[1], pos[1])
+					bbox[2] = math.Max(bbox[2], pos[0])
+					bbox[3] = math.Max(bbox[3], pos[1])
+					found = true
+				}
+			}
+		}
+	}
+	return bbox, found
+}
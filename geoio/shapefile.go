@@ -0,0 +1,423 @@
+package geoio
+
+// ESRI Shapefiles of polygons: the shapes in the .shp file, their offsets in
+// the .shx index and their attributes in the .dbf table. Exterior rings are
+// clockwise and interior rings counter-clockwise, the other way around from
+// polygol, and the rings of all polygons of a record come in one list.
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/engelsjk/polygol"
+)
+
+const (
+	shpFileCode   = 9994
+	shpVersion    = 1000
+	shpHeaderLen  = 100
+	shpNull       = 0
+	shpPolygon    = 5
+	shpPolygonZ   = 15
+	shpPolygonM   = 25
+	shxRecordLen  = 8
+	shpPolyHeader = 4 + 32 + 4 + 4 // shape type, box, number of parts and points
+)
+
+// ShapefileReader reads the polygon records of a Shapefile.
+type ShapefileReader struct {
+	shp io.Reader
+	// bytes of records left according to the file length in the header
+	remaining int64
+	dbf       *dbfReader
+	closers   []io.Closer
+}
+
+// OpenShapefile opens the .shp file at path, along with the .dbf file next to
+// it if there's one.
+func OpenShapefile(path string) (*ShapefileReader, error) {
+	base := strings.TrimSuffix(path, ".shp")
+	shp, err := os.Open(base + ".shp")
+	if err != nil {
+		return nil, err
+	}
+	closers := []io.Closer{shp}
+
+	var dbf io.Reader
+	if f, err := os.Open(base + ".dbf"); err == nil {
+		closers = append(closers, f)
+		dbf = f
+	} else if !errors.Is(err, os.ErrNotExist) {
+		shp.Close()
+		return nil, err
+	}
+
+	r, err := NewShapefileReader(shp, dbf)
+	if err != nil {
+		for _, c := range closers {
+			c.Close()
+		}
+		return nil, err
+	}
+	r.closers = closers
+	return r, nil
+}
+
+// NewShapefileReader reads records from the contents of a .shp file and of
+// its .dbf file, which may be nil for records without attributes.
+func NewShapefileReader(shp, dbf io.Reader) (*ShapefileReader, error) {
+	header := make([]byte, shpHeaderLen)
+	if _, err := io.ReadFull(shp, header); err != nil {
+		return nil, fmt.Errorf("shp: reading header: %w", err)
+	}
+	if code := binary.BigEndian.Uint32(header); code != shpFileCode {
+		return nil, fmt.Errorf("shp: not a shapefile, file code %d", code)
+	}
+	switch shapeType := binary.LittleEndian.Uint32(header[32:]); shapeType {
+	case shpNull, shpPolygon, shpPolygonZ, shpPolygonM:
+	default:
+		return nil, fmt.Errorf("shp: shape type %d isn't a polygon type", shapeType)
+	}
+
+	length := 2 * int64(binary.BigEndian.Uint32(header[24:]))
+	if length < shpHeaderLen {
+		return nil, fmt.Errorf("shp: file length %d is shorter than the header", length)
+	}
+
+	r := &ShapefileReader{shp: shp, remaining: length - shpHeaderLen}
+	if dbf != nil {
+		d, err := newDBFReader(dbf)
+		if err != nil {
+			return nil, err
+		}
+		r.dbf = d
+	}
+	return r, nil
+}
+
+// Fields returns the attribute fields of the .dbf file.
+func (r *ShapefileReader) Fields() []Field {
+	if r.dbf == nil {
+		return nil
+	}
+	fields := make([]Field, len(r.dbf.fields))
+	for i, f := range r.dbf.fields {
+		fields[i] = f.Field
+	}
+	return fields
+}
+
+// Next reads the next record. Null shapes read as empty geometries.
+func (r *ShapefileReader) Next() (polygol.Geom, map[string]any, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r.shp, header); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, io.EOF
+		}
+		return nil, nil, fmt.Errorf("shp: reading record header: %w", err)
+	}
+	// the content grows as it's read, so a corrupt length can't make for a
+	// huge allocation before the file runs out
+	length := 2 * int64(binary.BigEndian.Uint32(header[4:]))
+	if r.remaining -= int64(len(header)); length > r.remaining {
+		return nil, nil, fmt.Errorf("shp: record %d of %d bytes runs past the end of the file", binary.BigEndian.Uint32(header), length)
+	}
+	r.remaining -= length
+	content, err := io.ReadAll(io.LimitReader(r.shp, length))
+	if err == nil && int64(len(content)) < length {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("shp: reading record %d: %w", binary.BigEndian.Uint32(header), err)
+	}
+	geom, err := parseShpPolygon(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("shp: record %d: %w", binary.BigEndian.Uint32(header), err)
+	}
+
+	var props map[string]any
+	if r.dbf != nil {
+		props, err = r.dbf.next()
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("dbf: fewer records than in shp")
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return geom, props, nil
+}
+
+// Close closes the files opened by OpenShapefile.
+func (r *ShapefileReader) Close() error {
+	var err error
+	for _, c := range r.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func parseShpPolygon(content []byte) (polygol.Geom, error) {
+	if len(content) < 4 {
+		return nil, errors.New("record too short")
+	}
+	switch shapeType := binary.LittleEndian.Uint32(content); shapeType {
+	case shpNull:
+		return polygol.Geom{}, nil
+	case shpPolygon, shpPolygonZ, shpPolygonM:
+	default:
+		return nil, fmt.Errorf("shape type %d isn't a polygon type", shapeType)
+	}
+	if len(content) < shpPolyHeader {
+		return nil, errors.New("record too short")
+	}
+	numParts := int(binary.LittleEndian.Uint32(content[36:]))
+	numPoints := int(binary.LittleEndian.Uint32(content[40:]))
+	if numParts > len(content)/4 || numPoints > len(content)/16 ||
+		len(content) < shpPolyHeader+4*numParts+16*numPoints {
+		return nil, errors.New("record too short for its points")
+	}
+
+	parts := make([]int, numParts+1)
+	for i := 0; i < numParts; i++ {
+		parts[i] = int(binary.LittleEndian.Uint32(content[shpPolyHeader+4*i:]))
+	}
+	parts[numParts] = numPoints
+
+	points := content[shpPolyHeader+4*numParts:]
+	rings := make([][][]float64, 0, numParts)
+	for i := 0; i < numParts; i++ {
+		if parts[i] > parts[i+1] || parts[i+1] > numPoints {
+			return nil, fmt.Errorf("invalid part %d", i)
+		}
+		ring := make([][]float64, 0, parts[i+1]-parts[i])
+		for j := parts[i]; j < parts[i+1]; j++ {
+			x := math.Float64frombits(binary.LittleEndian.Uint64(points[16*j:]))
+			y := math.Float64frombits(binary.LittleEndian.Uint64(points[16*j+8:]))
+			ring = append(ring, []float64{x, y})
+		}
+		if len(ring) > 0 {
+			rings = append(rings, ring)
+		}
+	}
+	return groupRings(rings), nil
+}
+
+// groupRings sorts the rings of a record into polygons: every clockwise ring
+// is an exterior ring, and every other ring is an interior ring of the
+// smallest exterior ring around it, or an exterior ring itself if there's
+// none.
+func groupRings(rings [][][]float64) polygol.Geom {
+	geom := polygol.Geom{}
+	shellAreas := []float64{}
+	holes := [][][]float64{}
+	for _, ring := range rings {
+		if area := ringSignedArea(ring); area < 0 {
+			geom = append(geom, [][][]float64{ring})
+			shellAreas = append(shellAreas, -area)
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+	for _, hole := range holes {
+		best := -1
+		for i := range geom {
+			if ringContains(geom[i][0], hole[0][0], hole[0][1]) && (best == -1 || shellAreas[i] < shellAreas[best]) {
+				best = i
+			}
+		}
+		if best == -1 {
+			geom = append(geom, [][][]float64{hole})
+			shellAreas = append(shellAreas, ringSignedArea(hole))
+			continue
+		}
+		geom[best] = append(geom[best], hole)
+	}
+	return geom
+}
+
+// ShapefileWriter writes polygon records to a Shapefile.
+type ShapefileWriter struct {
+	shp, shx *os.File
+	dbf      *dbfWriter
+	dbfFile  *os.File
+	offset   int // in 16-bit words, as in the shp header
+	count    int
+	bbox     [4]float64
+	hasBbox  bool
+}
+
+// CreateShapefile creates the .shp, .shx and .dbf files of a Shapefile at
+// path, with the given attribute fields. The headers are only complete once
+// the writer is closed.
+func CreateShapefile(path string, fields []Field) (*ShapefileWriter, error) {
+	base := strings.TrimSuffix(path, ".shp")
+	w := &ShapefileWriter{offset: shpHeaderLen / 2}
+	var err error
+	files := []**os.File{&w.shp, &w.shx, &w.dbfFile}
+	for i, ext := range []string{".shp", ".shx", ".dbf"} {
+		if *files[i], err = os.Create(base + ext); err != nil {
+			w.closeFiles()
+			return nil, err
+		}
+	}
+	if w.dbf, err = newDBFWriter(w.dbfFile, fields); err != nil {
+		w.closeFiles()
+		return nil, err
+	}
+	// headers are written for real on close
+	placeholder := make([]byte, shpHeaderLen)
+	for _, f := range []*os.File{w.shp, w.shx} {
+		if _, err := f.Write(placeholder); err != nil {
+			w.closeFiles()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Write writes a record, an empty geometry as a null shape.
+func (w *ShapefileWriter) Write(geom polygol.Geom, properties map[string]any) error {
+	content := shpPolygonContent(geom)
+
+	w.count++
+	record := make([]byte, 8, 8+len(content))
+	binary.BigEndian.PutUint32(record, uint32(w.count))
+	binary.BigEndian.PutUint32(record[4:], uint32(len(content)/2))
+	record = append(record, content...)
+	if _, err := w.shp.Write(record); err != nil {
+		return err
+	}
+
+	index := make([]byte, shxRecordLen)
+	binary.BigEndian.PutUint32(index, uint32(w.offset))
+	binary.BigEndian.PutUint32(index[4:], uint32(len(content)/2))
+	if _, err := w.shx.Write(index); err != nil {
+		return err
+	}
+	w.offset += len(record) / 2
+
+	if bbox, ok := geomBbox(geom); ok {
+		if !w.hasBbox {
+			w.bbox, w.hasBbox = bbox, true
+		} else {
+			w.bbox[0] = math.Min(w.bbox[0], bbox[0])
+			w.bbox[1] = math.Min(w.bbox[1], bbox[1])
+			w.bbox[2] = math.Max(w.bbox[2], bbox[2])
+			w.bbox[3] = math.Max(w.bbox[3], bbox[3])
+		}
+	}
+
+	return w.dbf.write(properties)
+}
+
+// shpPolygonContent encodes a geometry as a polygon shape, turning exterior
+// rings clockwise and interior rings counter-clockwise.
+func shpPolygonContent(geom polygol.Geom) []byte {
+	bbox, ok := geomBbox(geom)
+	if !ok {
+		content := make([]byte, 4)
+		binary.LittleEndian.PutUint32(content, shpNull)
+		return content
+	}
+
+	rings := [][][]float64{}
+	for _, poly := range geom {
+		for j, ring := range poly {
+			if len(ring) == 0 {
+				continue
+			}
+			// close the ring, as shapefiles require
+			first, last := ring[0], ring[len(ring)-1]
+			if first[0] != last[0] || first[1] != last[1] {
+				ring = append(ring[:len(ring):len(ring)], first)
+			}
+			if (j == 0) == (ringSignedArea(ring) > 0) {
+				reversed := make([][]float64, len(ring))
+				for k := range ring {
+					reversed[len(ring)-1-k] = ring[k]
+				}
+				ring = reversed
+			}
+			rings = append(rings, ring)
+		}
+	}
+
+	numPoints := 0
+	for _, ring := range rings {
+		numPoints += len(ring)
+	}
+	content := make([]byte, shpPolyHeader+4*len(rings)+16*numPoints)
+	binary.LittleEndian.PutUint32(content, shpPolygon)
+	for i, v := range bbox {
+		binary.LittleEndian.PutUint64(content[4+8*i:], math.Float64bits(v))
+	}
+	binary.LittleEndian.PutUint32(content[36:], uint32(len(rings)))
+	binary.LittleEndian.PutUint32(content[40:], uint32(numPoints))
+
+	pos := shpPolyHeader
+	start := 0
+	for _, ring := range rings {
+		binary.LittleEndian.PutUint32(content[pos:], uint32(start))
+		pos += 4
+		start += len(ring)
+	}
+	for _, ring := range rings {
+		for _, pt := range ring {
+			binary.LittleEndian.PutUint64(content[pos:], math.Float64bits(pt[0]))
+			binary.LittleEndian.PutUint64(content[pos+8:], math.Float64bits(pt[1]))
+			pos += 16
+		}
+	}
+	return content
+}
+
+// Close writes the headers and closes the files.
+func (w *ShapefileWriter) Close() error {
+	err := w.writeHeader(w.shp, w.offset)
+	if err == nil {
+		err = w.writeHeader(w.shx, (shpHeaderLen+shxRecordLen*w.count)/2)
+	}
+	if err == nil {
+		err = w.dbf.close()
+	}
+	if cerr := w.closeFiles(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (w *ShapefileWriter) writeHeader(f *os.File, length int) error {
+	header := make([]byte, shpHeaderLen)
+	binary.BigEndian.PutUint32(header, shpFileCode)
+	binary.BigEndian.PutUint32(header[24:], uint32(length))
+	binary.LittleEndian.PutUint32(header[28:], shpVersion)
+	binary.LittleEndian.PutUint32(header[32:], shpPolygon)
+	if w.hasBbox {
+		for i, v := range w.bbox {
+			binary.LittleEndian.PutUint64(header[36+8*i:], math.Float64bits(v))
+		}
+	}
+	_, err := f.WriteAt(header, 0)
+	return err
+}
+
+func (w *ShapefileWriter) closeFiles() error {
+	var err error
+	for _, f := range []*os.File{w.shp, w.shx, w.dbfFile} {
+		if f == nil {
+			continue
+		}
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,263 @@
+package geoio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/engelsjk/polygol"
+)
+
+var testFields = []Field{
+	{Name: "name", Type: String},
+	{Name: "count", Type: Int},
+	{Name: "ratio", Type: Float},
+	{Name: "ok", Type: Bool},
+}
+
+// testRecords are polygol geometries, counter-clockwise outside and clockwise
+// inside.
+var testRecords = []struct {
+	geom  polygol.Geom
+	props map[string]any
+}{
+	{
+		polygol.Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, {{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}}}},
+		map[string]any{"name": "square", "count": int64(3), "ratio": 0.25, "ok": true},
+	},
+	{
+		polygol.Geom{
+			{{{10, 0}, {11, 0}, {11, 1}, {10, 0}}},
+			{{{20, 0}, {22, 0}, {22, 2}, {20, 2}, {20, 0}}},
+		},
+		map[string]any{"name": "two", "count": int64(-7), "ratio": 1.5e-3, "ok": false},
+	},
+	{
+		polygol.Geom{},
+		map[string]any{"name": "", "count": nil, "ratio": nil, "ok": nil},
+	},
+}
+
+func writeTestShapefile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "test.shp")
+	w, err := CreateShapefile(path, testFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range testRecords {
+		if err := w.Write(r.geom, r.props); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestShapefileRoundTrip(t *testing.T) {
+	r, err := OpenShapefile(writeTestShapefile(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	fields := r.Fields()
+	if len(fields) != len(testFields) {
+		t.Fatalf("expected %d fields, got %d", len(testFields), len(fields))
+	}
+	for i, f := range fields {
+		if f.Name != testFields[i].Name || f.Type != testFields[i].Type {
+			t.Errorf("field %d: expected %s %s, got %s %s", i, testFields[i].Name, testFields[i].Type, f.Name, f.Type)
+		}
+	}
+
+	geoms, props, err := ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(geoms) != len(testRecords) {
+		t.Fatalf("expected %d records, got %d", len(testRecords), len(geoms))
+	}
+	for i, r := range testRecords {
+		// shapefiles turn the rings around, but polygol doesn't mind
+		area, err := polygol.XOR(geoms[i], r.geom)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(area) != 0 || len(geoms[i]) != len(r.geom) {
+			t.Errorf("record %d: expected %v, got %v", i, r.geom, geoms[i])
+		}
+		if !reflect.DeepEqual(props[i], r.props) {
+			t.Errorf("record %d: expected properties %v, got %v", i, r.props, props[i])
+		}
+	}
+}
+
+func TestShapefileFiles(t *testing.T) {
+	path := writeTestShapefile(t)
+	shp, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shx, err := os.ReadFile(path[:len(path)-4] + ".shx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := 2 * int(binary.BigEndian.Uint32(shp[24:])); n != len(shp) {
+		t.Errorf("shp header has length %d, file %d", n, len(shp))
+	}
+	if n := 2 * int(binary.BigEndian.Uint32(shx[24:])); n != len(shx) || n != shpHeaderLen+shxRecordLen*len(testRecords) {
+		t.Errorf("shx header has length %d, file %d", n, len(shx))
+	}
+	if !bytes.Equal(shp[28:36], shx[28:36]) || binary.LittleEndian.Uint32(shp[32:]) != shpPolygon {
+		t.Errorf("expected polygon headers")
+	}
+
+	// every index entry points at its record
+	for i := range testRecords {
+		offset := 2 * int(binary.BigEndian.Uint32(shx[shpHeaderLen+shxRecordLen*i:]))
+		if n := int(binary.BigEndian.Uint32(shp[offset:])); n != i+1 {
+			t.Errorf("index entry %d points at record %d", i, n)
+		}
+	}
+
+	// exterior rings are clockwise and interior ones counter-clockwise
+	content := shp[shpHeaderLen+8:]
+	parts := 2
+	points := content[shpPolyHeader+4*parts:]
+	ring := func(start, end int) [][]float64 {
+		ring := [][]float64{}
+		for j := start; j < end; j++ {
+			x := math.Float64frombits(binary.LittleEndian.Uint64(points[16*j:]))
+			y := math.Float64frombits(binary.LittleEndian.Uint64(points[16*j+8:]))
+			ring = append(ring, []float64{x, y})
+		}
+		return ring
+	}
+	if ringSignedArea(ring(0, 5)) >= 0 || ringSignedArea(ring(5, 10)) <= 0 {
+		t.Errorf("expected a clockwise shell and counter-clockwise hole")
+	}
+}
+
+func TestGroupRings(t *testing.T) {
+	cw := func(ring [][]float64) [][]float64 {
+		reversed := make([][]float64, len(ring))
+		for i := range ring {
+			reversed[len(ring)-1-i] = ring[i]
+		}
+		return reversed
+	}
+	outer := [][]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	inner := [][]float64{{2, 2}, {8, 2}, {8, 8}, {2, 8}, {2, 2}}
+	island := [][]float64{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}}
+	hole := [][]float64{{4.5, 4.5}, {5.5, 4.5}, {5.5, 5.5}, {4.5, 5.5}, {4.5, 4.5}}
+	orphan := [][]float64{{20, 20}, {21, 20}, {21, 21}, {20, 20}}
+
+	got := groupRings([][][]float64{hole, cw(outer), inner, orphan, cw(island)})
+	expected := polygol.Geom{
+		{cw(outer), inner},
+		{cw(island), hole},
+		{orphan},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestShapefileWithoutDBF(t *testing.T) {
+	path := writeTestShapefile(t)
+	shp, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shp.Close()
+
+	r, err := NewShapefileReader(shp, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := r.Fields(); len(fields) != 0 {
+		t.Errorf("expected no fields, got %v", fields)
+	}
+	geom, props, err := r.Next()
+	if err != nil || len(geom) != 1 || props != nil {
+		t.Errorf("expected one polygon and no properties, got %v %v %v", geom, props, err)
+	}
+}
+
+func TestShapefileErrors(t *testing.T) {
+	path := writeTestShapefile(t)
+	shp, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notShp := append([]byte{}, shp...)
+	notShp[3] = 0
+	if _, err := NewShapefileReader(bytes.NewReader(notShp), nil); err == nil {
+		t.Errorf("expected an error for a bad file code")
+	}
+
+	points := append([]byte{}, shp...)
+	binary.LittleEndian.PutUint32(points[32:], 1)
+	if _, err := NewShapefileReader(bytes.NewReader(points), nil); err == nil {
+		t.Errorf("expected an error for a point shapefile")
+	}
+
+	r, err := NewShapefileReader(bytes.NewReader(shp[:len(shp)-10]), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for err == nil {
+		_, _, err = r.Next()
+	}
+	if errors.Is(err, io.EOF) {
+		t.Errorf("expected an error for a truncated record, got EOF")
+	}
+
+	// a record claiming far more than the file holds
+	huge := append([]byte{}, shp...)
+	binary.BigEndian.PutUint32(huge[shpHeaderLen+4:], math.MaxUint32)
+	if r, err = NewShapefileReader(bytes.NewReader(huge), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.Next(); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("expected an error for a record longer than the file, got %v", err)
+	}
+
+	// and a header claiming far more than the file holds
+	binary.BigEndian.PutUint32(huge[24:], math.MaxUint32)
+	if r, err = NewShapefileReader(bytes.NewReader(huge), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.Next(); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("expected an error for a truncated record, got %v", err)
+	}
+
+	short := append([]byte{}, shp...)
+	binary.BigEndian.PutUint32(short[24:], 10)
+	if _, err := NewShapefileReader(bytes.NewReader(short), nil); err == nil {
+		t.Errorf("expected an error for a file length shorter than the header")
+	}
+
+	if _, err := CreateShapefile(filepath.Join(t.TempDir(), "x.shp"), []Field{{Name: "much_too_long"}}); err == nil {
+		t.Errorf("expected an error for a long field name")
+	}
+
+	w, err := CreateShapefile(filepath.Join(t.TempDir(), "x.shp"), testFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.Write(polygol.Geom{}, map[string]any{"count": "three"}); err == nil {
+		t.Errorf("expected an error for a string in an int field")
+	}
+}
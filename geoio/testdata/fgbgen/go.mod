@@ -0,0 +1,5 @@
+module fgbgen
+
+go 1.19
+
+require github.com/google/flatbuffers v1.11.0
@@ -0,0 +1,378 @@
+// Command fgbgen writes the FlatGeobuf fixtures of the geoio tests with the
+// FlatBuffers builder of github.com/google/flatbuffers rather than with the
+// encoder of geoio, so that the tests read tables laid out by another
+// implementation: shared vtables, fields left at their defaults, tables the
+// reader doesn't know and a packed R-tree index. Run it from this directory
+// with
+//
+//	go run .
+package main
+
+import (
+	"encoding/binary"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// Geometry and column types of the FlatGeobuf schemas.
+const (
+	typeUnknown      = 0
+	typePolygon      = 3
+	typeMultiPolygon = 6
+
+	columnBool     = 2
+	columnShort    = 3
+	columnInt      = 5
+	columnLong     = 7
+	columnFloat    = 9
+	columnDouble   = 10
+	columnString   = 11
+	columnDateTime = 13
+)
+
+type column struct {
+	name string
+	typ  byte
+}
+
+// polygon is a list of closed rings of x, y pairs.
+type polygon [][]float64
+
+type feature struct {
+	typ      byte
+	polygons []polygon
+	// properties by column index, nil for none
+	properties map[uint16]interface{}
+}
+
+type file struct {
+	name          string
+	geometryType  byte
+	columns       []column
+	indexNodeSize uint16
+	features      []feature
+}
+
+func main() {
+	files := []file{
+		{
+			name:         "polygons.fgb",
+			geometryType: typePolygon,
+			columns: []column{
+				{"name", columnString},
+				{"population", columnLong},
+				{"area", columnDouble},
+				{"coastal", columnBool},
+				{"rank", columnShort},
+				{"density", columnFloat},
+				{"code", columnInt},
+				{"updated", columnDateTime},
+			},
+			indexNodeSize: 16,
+			features: []feature{
+				{
+					polygons: []polygon{{{0, 0, 4, 0, 4, 4, 0, 4, 0, 0}, {1, 1, 1, 3, 3, 3, 3, 1, 1, 1}}},
+					properties: map[uint16]interface{}{
+						0: "square", 1: int64(5000000000), 2: 12.5, 3: true,
+						4: int16(-3), 5: float32(2.5), 6: int32(42), 7: "2020-01-02T03:04:05Z",
+					},
+				},
+				{
+					polygons:   []polygon{{{10, 0, 12, 0, 11, 2, 10, 0}}},
+					properties: map[uint16]interface{}{0: "triangle", 3: false},
+				},
+				{
+					polygons: []polygon{{{-5, -5, -4, -5, -4, -4, -5, -4, -5, -5}}},
+				},
+			},
+		},
+		{
+			name:         "mixed.fgb",
+			geometryType: typeUnknown,
+			columns:      []column{{"name", columnString}},
+			features: []feature{
+				{
+					typ: typeMultiPolygon,
+					polygons: []polygon{
+						{{0, 0, 1, 0, 1, 1, 0, 1, 0, 0}},
+						{{2, 2, 3, 2, 3, 3, 2, 3, 2, 2}, {2.25, 2.25, 2.25, 2.75, 2.75, 2.75, 2.75, 2.25, 2.25, 2.25}},
+					},
+					properties: map[uint16]interface{}{0: "two squares"},
+				},
+				{
+					typ:        typePolygon,
+					polygons:   []polygon{{{5, 5, 6, 5, 6, 6, 5, 5}}},
+					properties: map[uint16]interface{}{0: "triangle"},
+				},
+				{
+					properties: map[uint16]interface{}{0: "no geometry"},
+				},
+			},
+		},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join("..", f.name), f.encode(), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func (f file) encode() []byte {
+	out := []byte("fgb\x03fgb\x01")
+	out = append(out, sizePrefixed(f.header())...)
+
+	features := make([][]byte, len(f.features))
+	for i, feat := range f.features {
+		features[i] = sizePrefixed(feat.encode(f.geometryType))
+	}
+	if f.indexNodeSize > 0 {
+		out = append(out, f.index(features)...)
+	}
+	for _, feat := range features {
+		out = append(out, feat...)
+	}
+	return out
+}
+
+func sizePrefixed(buf []byte) []byte {
+	out := make([]byte, 4, 4+len(buf))
+	binary.LittleEndian.PutUint32(out, uint32(len(buf)))
+	return append(out, buf...)
+}
+
+func (f file) header() []byte {
+	b := flatbuffers.NewBuilder(0)
+
+	columns := make([]flatbuffers.UOffsetT, len(f.columns))
+	for i, c := range f.columns {
+		name := b.CreateString(c.name)
+		b.StartObject(11)
+		b.PrependUOffsetTSlot(0, name, 0)
+		b.PrependByteSlot(1, c.typ, 0)
+		columns[i] = b.EndObject()
+	}
+	b.StartVector(4, len(columns), 4)
+	for i := len(columns) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(columns[i])
+	}
+	columnsVec := b.EndVector(len(columns))
+
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, feat := range f.features {
+		if x0, y0, x1, y1, ok := feat.bbox(); ok {
+			minX, minY = math.Min(minX, x0), math.Min(minY, y0)
+			maxX, maxY = math.Max(maxX, x1), math.Max(maxY, y1)
+		}
+	}
+	b.StartVector(8, 4, 8)
+	for _, v := range []float64{maxY, maxX, minY, minX} {
+		b.PrependFloat64(v)
+	}
+	envelope := b.EndVector(4)
+
+	// a Crs table, which the reader has no use for
+	org := b.CreateString("EPSG")
+	b.StartObject(6)
+	b.PrependUOffsetTSlot(0, org, 0)
+	b.PrependInt32Slot(1, 4326, 0)
+	crs := b.EndObject()
+
+	name := b.CreateString(f.name)
+	b.StartObject(14)
+	b.PrependUOffsetTSlot(0, name, 0)
+	b.PrependUOffsetTSlot(1, envelope, 0)
+	b.PrependByteSlot(2, f.geometryType, 0)
+	b.PrependUOffsetTSlot(7, columnsVec, 0)
+	b.PrependUint64Slot(8, uint64(len(f.features)), 0)
+	// left out when it's the default of 16
+	b.PrependUint16Slot(9, f.indexNodeSize, 16)
+	b.PrependUOffsetTSlot(10, crs, 0)
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+func (feat feature) encode(headerType byte) []byte {
+	b := flatbuffers.NewBuilder(0)
+
+	var geometry flatbuffers.UOffsetT
+	switch {
+	case len(feat.polygons) == 0:
+	case feat.typ == typeMultiPolygon || (feat.typ == typeUnknown && headerType == typeMultiPolygon):
+		parts := make([]flatbuffers.UOffsetT, len(feat.polygons))
+		for i, poly := range feat.polygons {
+			parts[i] = polygonGeometry(b, poly, typeUnknown)
+		}
+		b.StartVector(4, len(parts), 4)
+		for i := len(parts) - 1; i >= 0; i-- {
+			b.PrependUOffsetT(parts[i])
+		}
+		partsVec := b.EndVector(len(parts))
+		b.StartObject(8)
+		b.PrependUOffsetTSlot(7, partsVec, 0)
+		b.PrependByteSlot(6, feat.typ, 0)
+		geometry = b.EndObject()
+	default:
+		geometry = polygonGeometry(b, feat.polygons[0], feat.typ)
+	}
+
+	var properties flatbuffers.UOffsetT
+	if len(feat.properties) > 0 {
+		data := []byte{}
+		for i := uint16(0); i < 16; i++ {
+			v, ok := feat.properties[i]
+			if !ok {
+				continue
+			}
+			data = binary.LittleEndian.AppendUint16(data, i)
+			switch v := v.(type) {
+			case string:
+				data = binary.LittleEndian.AppendUint32(data, uint32(len(v)))
+				data = append(data, v...)
+			case int64:
+				data = binary.LittleEndian.AppendUint64(data, uint64(v))
+			case int32:
+				data = binary.LittleEndian.AppendUint32(data, uint32(v))
+			case int16:
+				data = binary.LittleEndian.AppendUint16(data, uint16(v))
+			case float64:
+				data = binary.LittleEndian.AppendUint64(data, math.Float64bits(v))
+			case float32:
+				data = binary.LittleEndian.AppendUint32(data, math.Float32bits(v))
+			case bool:
+				if v {
+					data = append(data, 1)
+				} else {
+					data = append(data, 0)
+				}
+			}
+		}
+		properties = b.CreateByteVector(data)
+	}
+
+	b.StartObject(3)
+	if geometry != 0 {
+		b.PrependUOffsetTSlot(0, geometry, 0)
+	}
+	if properties != 0 {
+		b.PrependUOffsetTSlot(1, properties, 0)
+	}
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+// polygonGeometry writes a Geometry table of a polygon, with ends only if it
+// has holes.
+func polygonGeometry(b *flatbuffers.Builder, poly polygon, typ byte) flatbuffers.UOffsetT {
+	coords := []float64{}
+	ends := []uint32{}
+	for _, ring := range poly {
+		coords = append(coords, ring...)
+		ends = append(ends, uint32(len(coords)/2))
+	}
+
+	var endsVec flatbuffers.UOffsetT
+	if len(ends) > 1 {
+		b.StartVector(4, len(ends), 4)
+		for i := len(ends) - 1; i >= 0; i-- {
+			b.PrependUint32(ends[i])
+		}
+		endsVec = b.EndVector(len(ends))
+	}
+	b.StartVector(8, len(coords), 8)
+	for i := len(coords) - 1; i >= 0; i-- {
+		b.PrependFloat64(coords[i])
+	}
+	xy := b.EndVector(len(coords))
+
+	b.StartObject(8)
+	if endsVec != 0 {
+		b.PrependUOffsetTSlot(0, endsVec, 0)
+	}
+	b.PrependUOffsetTSlot(1, xy, 0)
+	b.PrependByteSlot(6, typ, 0)
+	return b.EndObject()
+}
+
+func (feat feature) bbox() (minX, minY, maxX, maxY float64, ok bool) {
+	minX, minY, maxX, maxY = math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, poly := range feat.polygons {
+		for _, ring := range poly {
+			for i := 0; i+1 < len(ring); i += 2 {
+				minX, minY = math.Min(minX, ring[i]), math.Min(minY, ring[i+1])
+				maxX, maxY = math.Max(maxX, ring[i]), math.Max(maxY, ring[i+1])
+				ok = true
+			}
+		}
+	}
+	return
+}
+
+// index writes the packed R-tree over the features: the levels from the root
+// down to the leaves, every node a bbox and an offset, which for a leaf is
+// the position of its feature after the index and otherwise the position of
+// its first child node.
+func (f file) index(features [][]byte) []byte {
+	nodeSize := int(f.indexNodeSize)
+	type node struct {
+		minX, minY, maxX, maxY float64
+		offset                 uint64
+	}
+
+	leaves := make([]node, len(f.features))
+	offset := uint64(0)
+	for i, feat := range f.features {
+		minX, minY, maxX, maxY, _ := feat.bbox()
+		leaves[i] = node{minX, minY, maxX, maxY, offset}
+		offset += uint64(len(features[i]))
+	}
+
+	// the levels from the leaves up
+	levels := [][]node{leaves}
+	for {
+		below := levels[len(levels)-1]
+		level := []node{}
+		for i := 0; i < len(below); i += nodeSize {
+			parent := node{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1), 0}
+			for _, child := range below[i:min(i+nodeSize, len(below))] {
+				parent.minX, parent.minY = math.Min(parent.minX, child.minX), math.Min(parent.minY, child.minY)
+				parent.maxX, parent.maxY = math.Max(parent.maxX, child.maxX), math.Max(parent.maxY, child.maxY)
+			}
+			parent.offset = uint64(i)
+			level = append(level, parent)
+		}
+		levels = append(levels, level)
+		if len(level) == 1 {
+			break
+		}
+	}
+
+	// children are numbered within their level, so shift them by the nodes
+	// of the levels above theirs once the root is first
+	out := []byte{}
+	start := 0
+	for l := len(levels) - 1; l >= 0; l-- {
+		below := start + len(levels[l])
+		for _, n := range levels[l] {
+			if l > 0 {
+				n.offset += uint64(below)
+			}
+			for _, v := range []float64{n.minX, n.minY, n.maxX, n.maxY} {
+				out = binary.LittleEndian.AppendUint64(out, math.Float64bits(v))
+			}
+			out = binary.LittleEndian.AppendUint64(out, n.offset)
+		}
+		start = below
+	}
+	return out
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
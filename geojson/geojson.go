@@ -1,18 +1,88 @@
 package geojson
 
-// The following code is a minimal implementation of GeoJSON unmarshalling for Polygons and MultiPolygons.
+// The following code is a minimal implementation of GeoJSON (RFC 7946) for use with polygol.
 // It is lightly modified but mostly taken from paulmach/go.geojson; see included LICENSE file as needed.
+//
+// Geometries hold coordinates as plain slices, a MultiPolygon being a [][][][]float64 like a
+// polygol.Geom, so that this package doesn't depend on polygol itself.
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 )
 
+const (
+	TypePoint              = "Point"
+	TypeMultiPoint         = "MultiPoint"
+	TypeLineString         = "LineString"
+	TypeMultiLineString    = "MultiLineString"
+	TypePolygon            = "Polygon"
+	TypeMultiPolygon       = "MultiPolygon"
+	TypeGeometryCollection = "GeometryCollection"
+	TypeFeature            = "Feature"
+	TypeFeatureCollection  = "FeatureCollection"
+)
+
 type Geometry struct {
-	Type         string `json:"type"`
-	Polygon      [][][]float64
-	MultiPolygon [][][][]float64
+	Type            string `json:"type"`
+	BoundingBox     []float64
+	Point           []float64
+	MultiPoint      [][]float64
+	LineString      [][]float64
+	MultiLineString [][][]float64
+	Polygon         [][][]float64
+	MultiPolygon    [][][][]float64
+	Geometries      []*Geometry
+	// ForeignMembers holds any other members of the GeoJSON object.
+	ForeignMembers map[string]interface{}
+}
+
+func NewPolygonGeometry(polygon [][][]float64) *Geometry {
+	return &Geometry{Type: TypePolygon, Polygon: polygon}
+}
+
+func NewMultiPolygonGeometry(multiPolygon [][][][]float64) *Geometry {
+	return &Geometry{Type: TypeMultiPolygon, MultiPolygon: multiPolygon}
+}
+
+func NewGeometryCollection(geometries ...*Geometry) *Geometry {
+	return &Geometry{Type: TypeGeometryCollection, Geometries: geometries}
+}
+
+func UnmarshalGeometry(data []byte) (*Geometry, error) {
+	g := &Geometry{}
+	err := json.Unmarshal(data, g)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Geom flattens a Polygon, a MultiPolygon or a GeometryCollection of those
+// into the polygons of a multipolygon, as used by polygol.Geom.
+func (g *Geometry) Geom() ([][][][]float64, error) {
+	switch g.Type {
+	case TypePolygon:
+		return [][][][]float64{g.Polygon}, nil
+	case TypeMultiPolygon:
+		return g.MultiPolygon, nil
+	case TypeGeometryCollection:
+		geom := [][][][]float64{}
+		for i := 0; i < len(g.Geometries); i++ {
+			if g.Geometries[i] == nil {
+				continue
+			}
+			polys, err := g.Geometries[i].Geom()
+			if err != nil {
+				return nil, fmt.Errorf("geometry %d of collection: %w", i, err)
+			}
+			geom = append(geom, polys...)
+		}
+		return geom, nil
+	}
+	return nil, fmt.Errorf("geometry type %q has no area, only Polygon, MultiPolygon and GeometryCollection are supported", g.Type)
 }
 
 func (g *Geometry) UnmarshalJSON(data []byte) error {
@@ -21,6 +91,10 @@ func (g *Geometry) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
+	return g.decode(object)
+}
+
+func (g *Geometry) decode(object map[string]interface{}) error {
 	t, ok := object["type"]
 	if !ok {
 		return errors.New("type property not defined")
@@ -32,14 +106,168 @@ func (g *Geometry) UnmarshalJSON(data []byte) error {
 		return errors.New("type property not string")
 	}
 
+	var err error
 	switch g.Type {
-	case "Polygon":
+	case TypePoint:
+		g.Point, err = decodePosition(object["coordinates"])
+	case TypeMultiPoint:
+		g.MultiPoint, err = decodePositionSet(object["coordinates"])
+	case TypeLineString:
+		g.LineString, err = decodePositionSet(object["coordinates"])
+	case TypeMultiLineString:
+		g.MultiLineString, err = decodePathSet(object["coordinates"])
+	case TypePolygon:
 		g.Polygon, err = decodePathSet(object["coordinates"])
-	case "MultiPolygon":
+	case TypeMultiPolygon:
 		g.MultiPolygon, err = decodePolygonSet(object["coordinates"])
+	case TypeGeometryCollection:
+		g.Geometries, err = decodeGeometries(object["geometries"])
+	default:
+		return fmt.Errorf("unsupported geometry type %q", g.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	g.BoundingBox, err = decodeBoundingBox(object["bbox"])
+	if err != nil {
+		return err
+	}
+
+	g.ForeignMembers = foreignMembers(object, "type", "bbox", "coordinates", "geometries")
+	return nil
+}
+
+func (g Geometry) MarshalJSON() ([]byte, error) {
+	type geometry struct {
+		Type        string       `json:"type"`
+		BoundingBox []float64    `json:"bbox,omitempty"`
+		Coordinates interface{}  `json:"coordinates,omitempty"`
+		Geometries  *[]*Geometry `json:"geometries,omitempty"`
+	}
+	out := geometry{Type: g.Type, BoundingBox: g.BoundingBox}
+
+	switch g.Type {
+	case TypePoint:
+		out.Coordinates = orEmpty(g.Point != nil, g.Point, []float64{})
+	case TypeMultiPoint:
+		out.Coordinates = orEmpty(g.MultiPoint != nil, g.MultiPoint, [][]float64{})
+	case TypeLineString:
+		out.Coordinates = orEmpty(g.LineString != nil, g.LineString, [][]float64{})
+	case TypeMultiLineString:
+		out.Coordinates = orEmpty(g.MultiLineString != nil, g.MultiLineString, [][][]float64{})
+	case TypePolygon:
+		out.Coordinates = orEmpty(g.Polygon != nil, g.Polygon, [][][]float64{})
+	case TypeMultiPolygon:
+		out.Coordinates = orEmpty(g.MultiPolygon != nil, g.MultiPolygon, [][][][]float64{})
+	case TypeGeometryCollection:
+		geometries := g.Geometries
+		if geometries == nil {
+			geometries = []*Geometry{}
+		}
+		out.Geometries = &geometries
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q", g.Type)
+	}
+
+	return marshalWithForeignMembers(out, g.ForeignMembers)
+}
+
+type Feature struct {
+	Type        string         `json:"type"`
+	ID          interface{}    `json:"id,omitempty"`
+	BoundingBox []float64      `json:"bbox,omitempty"`
+	Geometry    *Geometry      `json:"geometry"`
+	Properties  map[string]any `json:"properties"`
+	// ForeignMembers holds any other members of the GeoJSON object.
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func NewFeature(geometry *Geometry) *Feature {
+	return &Feature{
+		Type:       TypeFeature,
+		Geometry:   geometry,
+		Properties: make(map[string]any),
+	}
+}
+
+func (f *Feature) UnmarshalJSON(data []byte) error {
+	type feature Feature
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, (*feature)(f)); err != nil {
+		return err
+	}
+	f.ForeignMembers = foreignMembers(object, "type", "id", "bbox", "geometry", "properties")
+	return nil
+}
+
+func (f Feature) MarshalJSON() ([]byte, error) {
+	type feature Feature
+	f.Type = TypeFeature
+	return marshalWithForeignMembers(feature(f), f.ForeignMembers)
+}
+
+type FeatureCollection struct {
+	Type        string     `json:"type"`
+	BoundingBox []float64  `json:"bbox,omitempty"`
+	Features    []*Feature `json:"features"`
+	// ForeignMembers holds any other members of the GeoJSON object.
+	ForeignMembers map[string]interface{} `json:"-"`
+}
+
+func NewFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{
+		Type:     TypeFeatureCollection,
+		Features: []*Feature{},
+	}
+}
+
+func (fc *FeatureCollection) AddFeature(feature *Feature) *FeatureCollection {
+	fc.Features = append(fc.Features, feature)
+	return fc
+}
+
+func (fc *FeatureCollection) UnmarshalJSON(data []byte) error {
+	type featureCollection FeatureCollection
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, (*featureCollection)(fc)); err != nil {
+		return err
 	}
+	fc.ForeignMembers = foreignMembers(object, "type", "bbox", "features")
+	return nil
+}
+
+func (fc FeatureCollection) MarshalJSON() ([]byte, error) {
+	type featureCollection FeatureCollection
+	fc.Type = TypeFeatureCollection
+	if fc.Features == nil {
+		fc.Features = []*Feature{}
+	}
+	return marshalWithForeignMembers(featureCollection(fc), fc.ForeignMembers)
+}
 
-	return err
+func UnmarshalFeatureCollection(data []byte) (*FeatureCollection, error) {
+	fc := &FeatureCollection{}
+	err := json.Unmarshal(data, fc)
+	if err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+func UnmarshalFeature(data []byte) (*Feature, error) {
+	fc := &Feature{}
+	err := json.Unmarshal(data, fc)
+	if err != nil {
+		return nil, err
+	}
+	return fc, nil
 }
 
 func decodePosition(data interface{}) ([]float64, error) {
@@ -115,31 +343,98 @@ func decodePolygonSet(data interface{}) ([][][][]float64, error) {
 	return result, nil
 }
 
-type Feature struct {
-	Type       string         `json:"type"`
-	Geometry   *Geometry      `json:"geometry"`
-	Properties map[string]any `json:'properties'`
-}
+func decodeGeometries(data interface{}) ([]*Geometry, error) {
+	geometries, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not a valid set of geometries, got %v", data)
+	}
 
-type FeatureCollection struct {
-	Type     string     `json:"type"`
-	Features []*Feature `json:"features"`
+	result := make([]*Geometry, 0, len(geometries))
+	for _, geometry := range geometries {
+		object, ok := geometry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("not a valid geometry, got %v", geometry)
+		}
+		g := &Geometry{}
+		if err := g.decode(object); err != nil {
+			return nil, err
+		}
+		result = append(result, g)
+	}
+
+	return result, nil
 }
 
-func UnmarshalFeatureCollection(data []byte) (*FeatureCollection, error) {
-	fc := &FeatureCollection{}
-	err := json.Unmarshal(data, fc)
+func decodeBoundingBox(data interface{}) ([]float64, error) {
+	if data == nil {
+		return nil, nil
+	}
+	bbox, err := decodePosition(data)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("not a valid bbox, got %v", data)
 	}
-	return fc, nil
+	return bbox, nil
 }
 
-func UnmarshalFeature(data []byte) (*Feature, error) {
-	fc := &Feature{}
-	err := json.Unmarshal(data, fc)
+// foreignMembers collects the members of an object other than the given
+// known ones, or nil if there are none.
+func foreignMembers(object map[string]interface{}, known ...string) map[string]interface{} {
+	var members map[string]interface{}
+	for k, v := range object {
+		isKnown := false
+		for _, kk := range known {
+			if k == kk {
+				isKnown = true
+				break
+			}
+		}
+		if isKnown {
+			continue
+		}
+		if members == nil {
+			members = make(map[string]interface{})
+		}
+		members[k] = v
+	}
+	return members
+}
+
+// marshalWithForeignMembers marshals v, which must marshal to an object,
+// followed by the foreign members that don't clash with its own members.
+func marshalWithForeignMembers(v interface{}, members map[string]interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(members) == 0 {
+		return data, err
+	}
+
+	var known map[string]json.RawMessage
+	if err := json.Unmarshal(data, &known); err != nil {
+		return nil, err
+	}
+	extra := make(map[string]interface{}, len(members))
+	for k, member := range members {
+		if _, ok := known[k]; !ok {
+			extra[k] = member
+		}
+	}
+	if len(extra) == 0 {
+		return data, nil
+	}
+	extraData, err := json.Marshal(extra)
 	if err != nil {
 		return nil, err
 	}
-	return fc, nil
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	buf.WriteByte(',')
+	buf.Write(extraData[1:])
+	return buf.Bytes(), nil
+}
+
+func orEmpty(ok bool, v interface{}, empty interface{}) interface{} {
+	if ok {
+		return v
+	}
+	return empty
 }
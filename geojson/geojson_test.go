@@ -0,0 +1,200 @@
+package geojson
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalGeometry(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected *Geometry
+	}{
+		{"point", `{"type":"Point","coordinates":[1,2]}`,
+			&Geometry{Type: TypePoint, Point: []float64{1, 2}}},
+		{"multipoint", `{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`,
+			&Geometry{Type: TypeMultiPoint, MultiPoint: [][]float64{{1, 2}, {3, 4}}}},
+		{"linestring", `{"type":"LineString","coordinates":[[1,2],[3,4]]}`,
+			&Geometry{Type: TypeLineString, LineString: [][]float64{{1, 2}, {3, 4}}}},
+		{"multilinestring", `{"type":"MultiLineString","coordinates":[[[1,2],[3,4]]]}`,
+			&Geometry{Type: TypeMultiLineString, MultiLineString: [][][]float64{{{1, 2}, {3, 4}}}}},
+		{"polygon with bbox", `{"type":"Polygon","bbox":[0,0,1,1],"coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`,
+			&Geometry{Type: TypePolygon, BoundingBox: []float64{0, 0, 1, 1}, Polygon: [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}},
+		{"multipolygon with foreign member", `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]]],"name":"a"}`,
+			&Geometry{Type: TypeMultiPolygon, MultiPolygon: [][][][]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}, ForeignMembers: map[string]interface{}{"name": "a"}}},
+		{"geometry collection", `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"GeometryCollection","geometries":[]}]}`,
+			&Geometry{Type: TypeGeometryCollection, Geometries: []*Geometry{
+				{Type: TypePoint, Point: []float64{1, 2}},
+				{Type: TypeGeometryCollection, Geometries: []*Geometry{}},
+			}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := UnmarshalGeometry([]byte(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(g, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, g)
+			}
+
+			// and back again
+			data, err := json.Marshal(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g2, err := UnmarshalGeometry(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(g2, g) {
+				t.Errorf("round trip mismatch: %s", data)
+			}
+		})
+	}
+}
+
+func TestUnmarshalGeometryErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"coordinates":[1,2]}`,
+		`{"type":1}`,
+		`{"type":"Circle","coordinates":[1,2]}`,
+		`{"type":"Feature","geometry":null}`,
+		`{"type":"Polygon"}`,
+		`{"type":"Polygon","coordinates":[[[0,"a"]]]}`,
+		`{"type":"Polygon","coordinates":[],"bbox":"a"}`,
+		`{"type":"GeometryCollection","geometries":[{"type":"Circle"}]}`,
+	} {
+		if _, err := UnmarshalGeometry([]byte(data)); err == nil {
+			t.Errorf("expected an error unmarshalling %s", data)
+		}
+	}
+}
+
+func TestMarshalGeometry(t *testing.T) {
+	testCases := []struct {
+		geometry *Geometry
+		expected string
+	}{
+		{NewPolygonGeometry([][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}),
+			`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`},
+		{NewMultiPolygonGeometry(nil),
+			`{"type":"MultiPolygon","coordinates":[]}`},
+		{NewGeometryCollection(),
+			`{"type":"GeometryCollection","geometries":[]}`},
+		{&Geometry{Type: TypePoint, Point: []float64{1, 2}, BoundingBox: []float64{1, 2, 1, 2}, ForeignMembers: map[string]interface{}{"type": "x", "name": "a"}},
+			`{"type":"Point","bbox":[1,2,1,2],"coordinates":[1,2],"name":"a"}`},
+	}
+	for _, tc := range testCases {
+		data, err := json.Marshal(tc.geometry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tc.expected {
+			t.Errorf("expected %s, got %s", tc.expected, data)
+		}
+	}
+
+	if _, err := json.Marshal(&Geometry{Type: "Circle"}); err == nil {
+		t.Error("expected an error marshalling an unsupported type")
+	}
+}
+
+func TestGeom(t *testing.T) {
+	square := [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}
+	other := [][][]float64{{{2, 2}, {3, 2}, {3, 3}, {2, 2}}}
+
+	geom, err := NewPolygonGeometry(square).Geom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(geom, [][][][]float64{square}) {
+		t.Errorf("unexpected polygon geom %v", geom)
+	}
+
+	collection := NewGeometryCollection(
+		NewPolygonGeometry(square),
+		NewGeometryCollection(NewMultiPolygonGeometry([][][][]float64{other})),
+	)
+	geom, err = collection.Geom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(geom, [][][][]float64{square, other}) {
+		t.Errorf("unexpected collection geom %v", geom)
+	}
+
+	_, err = (&Geometry{Type: TypeLineString}).Geom()
+	if err == nil || !strings.Contains(err.Error(), "LineString") {
+		t.Errorf("expected an error naming the unsupported type, got %v", err)
+	}
+	_, err = NewGeometryCollection(NewPolygonGeometry(square), &Geometry{Type: TypePoint}).Geom()
+	if err == nil {
+		t.Error("expected an error for a collection with a point")
+	}
+}
+
+func TestFeatureCollectionRoundTrip(t *testing.T) {
+	data := `{"type":"FeatureCollection","bbox":[0,0,1,1],"name":"squares","features":[` +
+		`{"type":"Feature","id":"a","geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]},"properties":{"n":1,"tags":["x"]},"title":"first"},` +
+		`{"type":"Feature","geometry":null,"properties":null}]}`
+
+	fc, err := UnmarshalFeatureCollection([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Features) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(fc.Features))
+	}
+	f := fc.Features[0]
+	if f.ID != "a" || f.Properties["n"] != 1.0 || f.ForeignMembers["title"] != "first" {
+		t.Errorf("unexpected feature %+v", f)
+	}
+	if fc.ForeignMembers["name"] != "squares" || !reflect.DeepEqual(fc.BoundingBox, []float64{0, 0, 1, 1}) {
+		t.Errorf("unexpected feature collection %+v", fc)
+	}
+	if fc.Features[1].Geometry != nil {
+		t.Errorf("expected a null geometry, got %+v", fc.Features[1].Geometry)
+	}
+
+	out, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected, actual interface{}
+	if err := json.Unmarshal([]byte(data), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(out, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("round trip mismatch:\n%s\n%s", data, out)
+	}
+}
+
+func TestMarshalFeature(t *testing.T) {
+	f := NewFeature(NewMultiPolygonGeometry([][][][]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}))
+	f.Properties["name"] = "a"
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]]]},"properties":{"name":"a"}}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	data, err = json.Marshal(NewFeatureCollection().AddFeature(&Feature{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":null,"properties":null}]}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
@@ -20,24 +20,37 @@ func (o *operation) newRingIn(ring [][]float64, poly *polyIn, isExterior bool) (
 		return nil, fmt.Errorf(`input geometry is not a valid polygon or multipolygon (empty)`)
 	}
 
+	points := make([]*point, 0, len(ring))
+	points = append(points, o.rounder.roundFloat(ring[0][0], ring[0][1]))
+	for i := 1; i < len(ring); i++ {
+		if len(ring[i]) < 2 {
+			return nil, fmt.Errorf(`input geometry is not a valid polygon or multipolygon (missing coordinates)`)
+		}
+		points = append(points, o.rounder.roundFloat(ring[i][0], ring[i][1]))
+	}
+
+	// clip to the operation's bbox, if any
+	if o.clipBbox != nil {
+		points = o.clipBbox.clipRing(points, o.rounder)
+		if len(points) == 0 {
+			return nil, nil
+		}
+	}
+
 	ri := &ringIn{}
 
 	ri.poly = poly
 	ri.isExterior = isExterior
 	ri.segments = []*segment{}
 
-	firstPoint := o.rounder.roundFloat(ring[0][0], ring[0][1])
+	firstPoint := points[0]
 
 	ri.bbox = Bbox{ll: firstPoint.Vector, ur: firstPoint.Vector}
 
 	prevPoint := firstPoint
-	for i := 1; i < len(ring); i++ {
+	for i := 1; i < len(points); i++ {
 
-		if len(ring[i]) < 2 {
-			return nil, fmt.Errorf(`input geometry is not a valid polygon or multipolygon (missing coordinates)`)
-		}
-
-		point := o.rounder.roundFloat(ring[i][0], ring[i][1])
+		point := points[i]
 
 		// skip repeated points
 		if point.x.equalTo(prevPoint.x) && point.y.equalTo(prevPoint.y) {
@@ -115,6 +128,10 @@ func (o *operation) newPolyIn(poly [][][]float64, multiPoly *multiPolyIn) (*poly
 	if err != nil {
 		return nil, err
 	}
+	// exterior ring was clipped away entirely
+	if exteriorRing == nil {
+		return nil, nil
+	}
 
 	pi.exteriorRing = exteriorRing
 	pi.bbox = pi.exteriorRing.bbox
@@ -125,6 +142,10 @@ func (o *operation) newPolyIn(poly [][][]float64, multiPoly *multiPolyIn) (*poly
 		if err != nil {
 			return nil, err
 		}
+		// interior ring was clipped away entirely
+		if ring == nil {
+			continue
+		}
 		if ring.bbox.ll.x.isLessThan(pi.bbox.ll.x) {
 			pi.bbox.ll.x = ring.bbox.ll.x
 		}
@@ -185,6 +206,10 @@ func (o *operation) newMultiPolyIn(multiPoly [][][][]float64, isSubject bool) (*
 		if err != nil {
 			return nil, err
 		}
+		// polygon was clipped away entirely
+		if poly == nil {
+			continue
+		}
 		if poly.bbox.ll.x.isLessThan(mpi.bbox.ll.x) {
 			mpi.bbox.ll.x = poly.bbox.ll.x
 		}
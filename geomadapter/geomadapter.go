@@ -0,0 +1,129 @@
+// Package geomadapter converts between twpayne/go-geom geometries and polygol
+// geometries, and wraps the polygol operations to take and return go-geom
+// MultiPolygons.
+//
+// Results are always XY MultiPolygons, an empty result being an empty
+// MultiPolygon, however many polygons they have. They take the SRID of the
+// first input.
+package geomadapter
+
+import (
+	"fmt"
+
+	"github.com/engelsjk/polygol"
+	"github.com/twpayne/go-geom"
+)
+
+// ToGeom converts a go-geom Polygon or MultiPolygon, or a GeometryCollection
+// of those, to a polygol geometry. Z and M coordinates are dropped.
+func ToGeom(g geom.T) (polygol.Geom, error) {
+	switch v := g.(type) {
+	case *geom.Polygon:
+		if v.Empty() {
+			return polygol.Geom{}, nil
+		}
+		return polygol.Geom{coordsToPolygon(v.Coords())}, nil
+	case *geom.MultiPolygon:
+		coords := v.Coords()
+		p := polygol.Geom{}
+		for i := range coords {
+			if len(coords[i]) == 0 {
+				continue
+			}
+			p = append(p, coordsToPolygon(coords[i]))
+		}
+		return p, nil
+	case *geom.GeometryCollection:
+		p := polygol.Geom{}
+		for i, g := range v.Geoms() {
+			polys, err := ToGeom(g)
+			if err != nil {
+				return nil, fmt.Errorf("geometry %d of collection: %w", i, err)
+			}
+			p = append(p, polys...)
+		}
+		return p, nil
+	case nil:
+		return polygol.Geom{}, nil
+	}
+	return nil, fmt.Errorf("go-geom geometry type %T has no area", g)
+}
+
+func coordsToPolygon(coords [][]geom.Coord) [][][]float64 {
+	poly := make([][][]float64, len(coords))
+	for i := range coords { // rings
+		poly[i] = make([][]float64, len(coords[i]))
+		for j := range coords[i] { // points
+			coord := coords[i][j]
+			poly[i][j] = []float64{coord.X(), coord.Y()}
+		}
+	}
+	return poly
+}
+
+// FromGeom converts a polygol geometry to an XY go-geom MultiPolygon.
+func FromGeom(p polygol.Geom) *geom.MultiPolygon {
+	coords := make([][][]geom.Coord, len(p))
+	for i := range p { // polygons
+		coords[i] = make([][]geom.Coord, len(p[i]))
+		for j := range p[i] { // rings
+			coords[i][j] = make([]geom.Coord, len(p[i][j]))
+			for k := range p[i][j] { // points
+				pt := p[i][j][k]
+				coords[i][j][k] = geom.Coord{pt[0], pt[1]}
+			}
+		}
+	}
+	return geom.NewMultiPolygon(geom.XY).MustSetCoords(coords)
+}
+
+func Union(mp *geom.MultiPolygon, more ...*geom.MultiPolygon) (*geom.MultiPolygon, error) {
+	return apply(polygol.Union, mp, more)
+}
+
+func Intersection(mp *geom.MultiPolygon, more ...*geom.MultiPolygon) (*geom.MultiPolygon, error) {
+	return apply(polygol.Intersection, mp, more)
+}
+
+func Difference(mp *geom.MultiPolygon, more ...*geom.MultiPolygon) (*geom.MultiPolygon, error) {
+	return apply(polygol.Difference, mp, more)
+}
+
+func XOR(mp *geom.MultiPolygon, more ...*geom.MultiPolygon) (*geom.MultiPolygon, error) {
+	return apply(polygol.XOR, mp, more)
+}
+
+func apply(
+	op func(p polygol.Geom, moreGeoms ...polygol.Geom) (polygol.Geom, error),
+	mp *geom.MultiPolygon, more []*geom.MultiPolygon,
+) (*geom.MultiPolygon, error) {
+	p, err := multiPolygonToGeom(mp)
+	if err != nil {
+		return nil, err
+	}
+	moreGeoms := make([]polygol.Geom, len(more))
+	for i := range more {
+		moreGeoms[i], err = multiPolygonToGeom(more[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	result, err := op(p, moreGeoms...)
+	if err != nil {
+		return nil, err
+	}
+	out := FromGeom(result)
+	if mp != nil {
+		out.SetSRID(mp.SRID())
+	}
+	return out, nil
+}
+
+// multiPolygonToGeom avoids handing ToGeom a typed nil pointer, which it
+// wouldn't see as nil.
+func multiPolygonToGeom(mp *geom.MultiPolygon) (polygol.Geom, error) {
+	if mp == nil {
+		return polygol.Geom{}, nil
+	}
+	return ToGeom(mp)
+}
@@ -0,0 +1,98 @@
+package geomadapter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/engelsjk/polygol"
+	"github.com/twpayne/go-geom"
+)
+
+var (
+	squareCoords = [][]geom.Coord{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}
+	otherCoords  = [][]geom.Coord{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}
+	squareGeom   = polygol.Geom{{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}}
+)
+
+func TestToGeom(t *testing.T) {
+	testCases := []struct {
+		name string
+		g    geom.T
+		geom polygol.Geom
+	}{
+		{"polygon", geom.NewPolygon(geom.XY).MustSetCoords(squareCoords), squareGeom},
+		{"polygon z", geom.NewPolygon(geom.XYZ).MustSetCoords([][]geom.Coord{{{0, 0, 9}, {2, 0, 9}, {2, 2, 9}, {0, 2, 9}, {0, 0, 9}}}), squareGeom},
+		{"empty polygon", geom.NewPolygon(geom.XY), polygol.Geom{}},
+		{"multipolygon", geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{squareCoords}), squareGeom},
+		{"collection", geom.NewGeometryCollection().MustPush(
+			geom.NewPolygon(geom.XY).MustSetCoords(squareCoords),
+			geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{otherCoords}),
+		), polygol.Geom{squareGeom[0], {{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}}},
+		{"nil", nil, polygol.Geom{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ToGeom(tc.g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(p, tc.geom) {
+				t.Errorf("expected %v, got %v", tc.geom, p)
+			}
+		})
+	}
+
+	if _, err := ToGeom(geom.NewPointFlat(geom.XY, []float64{0, 0})); err == nil {
+		t.Error("expected an error for a point")
+	}
+}
+
+func TestFromGeom(t *testing.T) {
+	mp := FromGeom(polygol.Geom{squareGeom[0]})
+	if !reflect.DeepEqual(mp.Coords(), [][][]geom.Coord{squareCoords}) {
+		t.Errorf("unexpected coords %v", mp.Coords())
+	}
+	if empty := FromGeom(polygol.Geom{}); empty.NumPolygons() != 0 || empty.Layout() != geom.XY {
+		t.Errorf("expected an empty XY multipolygon, got %#v", empty)
+	}
+}
+
+func TestOperations(t *testing.T) {
+	a := geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{squareCoords}).SetSRID(4326)
+	b := geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{otherCoords})
+
+	testCases := []struct {
+		name string
+		op   func(*geom.MultiPolygon, ...*geom.MultiPolygon) (*geom.MultiPolygon, error)
+		area float64
+	}{
+		{"union", Union, 7},
+		{"intersection", Intersection, 1},
+		{"difference", Difference, 3},
+		{"xor", XOR, 6},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.op(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if area := result.Area(); area != tc.area {
+				t.Errorf("expected area %v, got %v", tc.area, area)
+			}
+			if result.SRID() != 4326 {
+				t.Errorf("expected SRID 4326, got %d", result.SRID())
+			}
+		})
+	}
+
+	result, err := Union(nil, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Area() != 4 {
+		t.Errorf("expected area 4, got %v", result.Area())
+	}
+}
@@ -0,0 +1,193 @@
+package polygol
+
+import (
+	"fmt"
+	"sort"
+
+	splaytree "github.com/engelsjk/splay-tree"
+)
+
+// Location is where a point lies relative to a geometry.
+type Location int
+
+const (
+	// Exterior is outside of the geometry.
+	Exterior Location = iota
+	// Boundary is on a ring of the geometry.
+	Boundary
+	// Interior is inside of the geometry and not on any of its rings.
+	Interior
+)
+
+func (l Location) String() string {
+	switch l {
+	case Exterior:
+		return "exterior"
+	case Boundary:
+		return "boundary"
+	case Interior:
+		return "interior"
+	}
+	return fmt.Sprintf("Location(%d)", int(l))
+}
+
+// Points are located with a second sweep over the segments left over by the
+// sweep of the geometry, which no longer cross each other. The segments are
+// kept in a tree ordered along the sweep line as usual and every point is
+// looked up in that tree when the sweep reaches it: the point is on the
+// boundary if it's on a segment in the tree, and otherwise the segment right
+// below it tells whether it's inside the geometry.
+
+type locateEventType int
+
+const (
+	// at the same x, segments are added before points are looked up and
+	// removed after, so that points at their endpoints see them
+	locateAdd locateEventType = iota
+	locateQuery
+	locateRemove
+)
+
+type locateEvent struct {
+	x       BigNumber
+	typ     locateEventType
+	segment *segment
+	point   *point
+	index   int
+}
+
+// locateCompare orders segments as the sweep line does and a point relative
+// to the segments in the tree, 0 meaning the point is on the segment.
+func locateCompare(a, b interface{}) int {
+	aPt, aIsPt := a.(*point)
+	bPt, bIsPt := b.(*point)
+	switch {
+	case aIsPt && bIsPt:
+		return 0
+	case aIsPt:
+		return comparePointToSegment(aPt, b.(*segment))
+	case bIsPt:
+		return -comparePointToSegment(bPt, a.(*segment))
+	}
+	return segmentCompare(a, b)
+}
+
+// comparePointToSegment assumes the point is within the x range of the
+// segment.
+func comparePointToSegment(pt *point, seg *segment) int {
+	if seg.leftSE.point.x.equalTo(seg.rightSE.point.x) {
+		// vertical segment, only as long as the point is on it
+		if pt.y.isLessThan(seg.leftSE.point.y) {
+			return -1
+		}
+		if pt.y.isGreaterThan(seg.rightSE.point.y) {
+			return 1
+		}
+		return 0
+	}
+	// comparePoint is positive above the segment and negative below
+	return seg.comparePoint(pt)
+}
+
+func (o *operation) locate(geom Geom, points [][]float64) ([]Location, error) {
+
+	locations := make([]Location, len(points))
+	for i := 0; i < len(points); i++ {
+		if len(points[i]) < 2 {
+			return nil, fmt.Errorf("point %d has %d coordinates, expected at least 2", i, len(points[i]))
+		}
+	}
+	if len(geom) == 0 || len(points) == 0 {
+		return locations, nil
+	}
+
+	o.rounder.reset()
+	defer o.rounder.reset()
+
+	multiPolys, err := o.geomsToMultiPolys(geom, nil)
+	if err != nil {
+		return nil, err
+	}
+	sweepLine, err := o.sweep(multiPolys)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []locateEvent{}
+	for i := 0; i < len(sweepLine.segments); i++ {
+		seg := sweepLine.segments[i]
+		if seg.consumedBy != nil {
+			continue
+		}
+		events = append(events,
+			locateEvent{x: seg.leftSE.point.x, typ: locateAdd, segment: seg},
+			locateEvent{x: seg.rightSE.point.x, typ: locateRemove, segment: seg},
+		)
+	}
+	for i := 0; i < len(points); i++ {
+		// round like the geometry was, so that points snap to its vertices
+		pt := o.rounder.roundFloat(points[i][0], points[i][1])
+		events = append(events, locateEvent{x: pt.x, typ: locateQuery, point: pt, index: i})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].x.equalTo(events[j].x) {
+			return events[i].x.isLessThan(events[j].x)
+		}
+		return events[i].typ < events[j].typ
+	})
+
+	tree := splaytree.New(locateCompare)
+	for i := 0; i < len(events); i++ {
+		event := events[i]
+		switch event.typ {
+		case locateAdd:
+			tree.Add(event.segment)
+		case locateRemove:
+			tree.Remove(event.segment)
+		case locateQuery:
+			locations[event.index] = locateInTree(tree, event.point)
+		}
+	}
+	return locations, nil
+}
+
+func locateInTree(tree *splaytree.SplayTree, pt *point) Location {
+	node := tree.Add(pt)
+	if seg, ok := node.Item().(*segment); ok {
+		// segments with the geometry on both sides, from overlapping
+		// polygons, or on neither side, from spikes, aren't part of the
+		// boundary
+		if isBoundarySegment(seg) {
+			return Boundary
+		}
+		for _, step := range []func(*splaytree.Node) *splaytree.Node{tree.Prev, tree.Next} {
+			for n := step(node); n != nil && locateCompare(pt, n.Item()) == 0; n = step(n) {
+				if isBoundarySegment(n.Item().(*segment)) {
+					return Boundary
+				}
+			}
+		}
+		if len(seg.afterState().multiPolys) > 0 {
+			return Interior
+		}
+		return Exterior
+	}
+	defer tree.Remove(pt)
+
+	// vertical segments below the point say nothing about what's above them
+	for prev := tree.Prev(node); prev != nil; prev = tree.Prev(prev) {
+		seg := prev.Item().(*segment)
+		if seg.leftSE.point.x.equalTo(seg.rightSE.point.x) {
+			continue
+		}
+		if len(seg.afterState().multiPolys) > 0 {
+			return Interior
+		}
+		return Exterior
+	}
+	return Exterior
+}
+
+func isBoundarySegment(seg *segment) bool {
+	return (len(seg.beforeState().multiPolys) > 0) != (len(seg.afterState().multiPolys) > 0)
+}
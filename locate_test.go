@@ -0,0 +1,85 @@
+package polygol
+
+import (
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	squareWithHole := Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+	}}
+	diamond := Geom{{{{2, 0}, {4, 2}, {2, 4}, {0, 2}, {2, 0}}}}
+	// zero-width spikes out of the square and into it
+	spikes := Geom{{{{0, 0}, {4, 0}, {4, 1}, {8, 1}, {4, 1}, {4, 3}, {2, 3}, {4, 3}, {4, 4}, {0, 4}, {0, 0}}}}
+
+	testCases := []struct {
+		name     string
+		geom     Geom
+		x, y     float64
+		location Location
+	}{
+		{"interior", squareWithHole, 0.5, 0.5, Interior},
+		{"in the hole", squareWithHole, 2, 2, Exterior},
+		{"outside", squareWithHole, 5, 2, Exterior},
+		{"left of", squareWithHole, -1, 2, Exterior},
+		{"on an edge", squareWithHole, 2, 0, Boundary},
+		{"on a vertical edge", squareWithHole, 0, 2, Boundary},
+		{"on a hole edge", squareWithHole, 3, 2, Boundary},
+		{"on a vertex", squareWithHole, 4, 4, Boundary},
+		{"above a vertical edge", squareWithHole, 4, 5, Exterior},
+		{"between vertical edges", squareWithHole, 1, 3.5, Interior},
+		{"on a diagonal edge", diamond, 1, 1, Boundary},
+		{"near a diagonal edge", diamond, 1, 1.5, Interior},
+		{"within epsilon of an edge", diamond, 1, 1 + 1e-16, Boundary},
+		{"below a vertex", diamond, 2, -1, Exterior},
+		{"between vertices", diamond, 2, 2, Interior},
+		{"on a spike out", spikes, 6, 1, Exterior},
+		{"at the tip of a spike out", spikes, 8, 1, Exterior},
+		{"on a spike in", spikes, 3, 3, Interior},
+		{"where a spike leaves", spikes, 4, 1, Boundary},
+		{"empty", Geom{}, 0, 0, Exterior},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			location, err := Locate(tc.geom, tc.x, tc.y)
+			terr(t, err)
+			if location != tc.location {
+				t.Errorf("expected %s, got %s", tc.location, location)
+			}
+		})
+	}
+}
+
+func TestLocateAll(t *testing.T) {
+	geom := Geom{
+		{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+		{{{2, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 0}}},
+		{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}},
+	}
+	points := [][]float64{
+		{0.5, 0.5}, // inside of one polygon
+		{2, 0.5},   // on an edge shared by two polygons
+		{2, 1.5},   // where three polygons overlap
+		{3, 3},     // on a vertex
+		{0.5, 2.5}, // outside
+		{3, 1},     // on edges within the union
+		{2, 1.5},   // a duplicate point
+	}
+	expected := []Location{Interior, Interior, Interior, Boundary, Exterior, Interior, Interior}
+
+	locations, err := LocateAll(geom, points)
+	terr(t, err)
+	if len(locations) != len(expected) {
+		t.Fatalf("expected %d locations, got %d", len(expected), len(locations))
+	}
+	for i := 0; i < len(expected); i++ {
+		if locations[i] != expected[i] {
+			t.Errorf("point %v: expected %s, got %s", points[i], expected[i], locations[i])
+		}
+	}
+
+	_, err = LocateAll(geom, [][]float64{{1}})
+	expect(t, err != nil)
+}
@@ -0,0 +1,56 @@
+package polygol
+
+// MakeValid repairs a geometry by resolving it through the sweep on its own,
+// which nodes all rings against each other and rebuilds the output rings from
+// the edges separating filled from unfilled area. The repair policy follows
+// the fill rule:
+//
+//   - NonZero keeps all area: bow-ties and self-overlapping rings keep every
+//     lobe, overlapping or duplicate shells are merged, nested shells are
+//     swallowed by the outer one, holes are holes whatever their orientation
+//     and a hole only removes area from the shell of its own polygon.
+//   - EvenOdd alternates: area covered by an even number of rings is removed,
+//     so duplicate shells cancel out, a shell nested in another becomes a
+//     hole and the area where two holes overlap is filled again.
+//
+// Either way the output has exterior rings counter-clockwise and interior
+// rings clockwise, touching only at points. Before the sweep, positions with
+// fewer than two coordinates and empty rings are dropped, as are polygons
+// without an exterior ring.
+func (p *Polygol) MakeValid(geom Geom) (Geom, error) {
+	return p.result("union", cleanGeom(geom), nil)
+}
+
+// makeValid is MakeValid without the output options, for the operations
+// building on it.
+func (p *Polygol) makeValid(geom Geom) (Geom, error) {
+	return p.run("union", cleanGeom(geom), nil)
+}
+
+// cleanGeom drops the parts of a geometry that can't be turned into segments.
+func cleanGeom(geom Geom) Geom {
+	cleaned := Geom{}
+	for i := 0; i < len(geom); i++ {
+		poly := [][][]float64{}
+		for j := 0; j < len(geom[i]); j++ {
+			ring := [][]float64{}
+			for k := 0; k < len(geom[i][j]); k++ {
+				if len(geom[i][j][k]) >= 2 {
+					ring = append(ring, geom[i][j][k])
+				}
+			}
+			if len(ring) == 0 {
+				// no exterior ring, no polygon
+				if j == 0 {
+					break
+				}
+				continue
+			}
+			poly = append(poly, ring)
+		}
+		if len(poly) > 0 {
+			cleaned = append(cleaned, poly)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,111 @@
+package polygol
+
+import (
+	"testing"
+)
+
+func TestMakeValid(t *testing.T) {
+	evenOdd := &Polygol{FillRule: EvenOdd}
+
+	testCases := []struct {
+		name     string
+		geom     Geom
+		nonZero  Geom
+		evenOdd  Geom
+		sameBoth bool
+	}{
+		{
+			name:     "bow-tie",
+			geom:     Geom{{{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}}},
+			nonZero:  Geom{{{{0, 0}, {1, 1}, {0, 2}, {0, 0}}}, {{{1, 1}, {2, 0}, {2, 2}, {1, 1}}}},
+			sameBoth: true,
+		},
+		{
+			name:     "inverted hole",
+			geom:     Geom{{{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}}, {{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}},
+			nonZero:  Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}, {{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}}}},
+			sameBoth: true,
+		},
+		{
+			name:    "duplicate shells",
+			geom:    Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}, {{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}},
+			nonZero: Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}},
+			evenOdd: Geom{},
+		},
+		{
+			name:    "nested shells",
+			geom:    Geom{{{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}}}, {{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}}},
+			nonZero: Geom{{{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}}}},
+			evenOdd: Geom{{{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}}, {{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}}}},
+		},
+		{
+			name: "overlapping holes",
+			geom: Geom{{
+				{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}},
+				{{1, 1}, {4, 1}, {4, 4}, {1, 4}, {1, 1}},
+				{{2, 2}, {5, 2}, {5, 5}, {2, 5}, {2, 2}},
+			}},
+			nonZero: Geom{{
+				{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}},
+				{{1, 1}, {1, 4}, {2, 4}, {2, 5}, {5, 5}, {5, 2}, {4, 2}, {4, 1}, {1, 1}},
+			}},
+			evenOdd: Geom{{
+				{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}},
+				{{1, 1}, {1, 4}, {2, 4}, {2, 2}, {4, 2}, {4, 1}, {1, 1}},
+				{{2, 4}, {2, 5}, {5, 5}, {5, 2}, {4, 2}, {4, 4}, {2, 4}},
+			}},
+		},
+		{
+			name:    "ring winding twice",
+			geom:    Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}},
+			nonZero: Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}},
+			evenOdd: Geom{},
+		},
+		{
+			name:    "self-overlapping ring",
+			geom:    Geom{{{{0, 0}, {4, 0}, {4, 3}, {1, 3}, {1, 1}, {3, 1}, {3, 4}, {0, 4}, {0, 0}}}},
+			nonZero: Geom{{{{0, 0}, {4, 0}, {4, 3}, {3, 3}, {3, 4}, {0, 4}, {0, 0}}}},
+			evenOdd: Geom{{{{0, 0}, {4, 0}, {4, 3}, {3, 3}, {3, 4}, {0, 4}, {0, 0}}, {{3, 3}, {3, 1}, {1, 1}, {1, 3}, {3, 3}}}},
+		},
+		{
+			name:     "empty rings and missing coordinates",
+			geom:     Geom{{{}}, {{{0, 0}, {4, 0}, {4}, {4, 4}, {0, 4}, {0, 0}}, {}}},
+			nonZero:  Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}},
+			sameBoth: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := MakeValid(tc.geom)
+			terr(t, err)
+			expect(t, equalMultiPoly(result, tc.nonZero))
+			expect(t, len(mustValidate(t, result)) == 0)
+
+			expected := tc.evenOdd
+			if tc.sameBoth {
+				expected = tc.nonZero
+			}
+			result, err = evenOdd.MakeValid(tc.geom)
+			terr(t, err)
+			expect(t, equalMultiPoly(result, expected))
+			expect(t, len(mustValidate(t, result)) == 0)
+		})
+	}
+}
+
+func TestFillRuleEvenOdd(t *testing.T) {
+	// the fill rule applies to every input of an operation
+	a := Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}, {{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}}
+	b := Geom{{{{2, 0}, {6, 0}, {6, 4}, {2, 4}, {2, 0}}}}
+
+	p := New()
+	p.FillRule = EvenOdd
+	result, err := p.Intersection(a, b)
+	terr(t, err)
+	expect(t, equalMultiPoly(result, Geom{{{{2, 0}, {4, 0}, {4, 4}, {2, 4}, {2, 3}, {3, 3}, {3, 1}, {2, 1}, {2, 0}}}}))
+
+	result, err = Intersection(a, b)
+	terr(t, err)
+	expect(t, equalMultiPoly(result, Geom{{{{2, 0}, {4, 0}, {4, 4}, {2, 4}, {2, 0}}}}))
+}
@@ -0,0 +1,150 @@
+package polygol
+
+// The measures below are computed with BigNumber arithmetic, the same as the
+// orientation and colinearity decisions of the sweep, so that a ring the sweep
+// output as an exterior ring never measures as a negative area.
+
+// Area returns the area of the geometry: the area of the exterior ring of
+// every polygon less the area of its interior rings, whatever the orientation
+// of the rings.
+func (g Geom) Area() float64 {
+	area := bigZero()
+	for i := 0; i < len(g); i++ {
+		area = area.plus(polyArea2(g[i]))
+	}
+	return area.div(newBigNumber(2)).number()
+}
+
+// Perimeter returns the summed length of all rings of the geometry, interior
+// rings included.
+func (g Geom) Perimeter() float64 {
+	perimeter := bigZero()
+	for i := 0; i < len(g); i++ {
+		for j := 0; j < len(g[i]); j++ {
+			perimeter = perimeter.plus(ringLength(ringPoints(g[i][j])))
+		}
+	}
+	return perimeter.number()
+}
+
+// Centroid returns the center of mass of the area of the geometry. For a
+// geometry without area it falls back to the center of its edges, or of its
+// points if they're all the same. It returns nil for an empty geometry.
+func (g Geom) Centroid() []float64 {
+	area2 := bigZero()
+	cx, cy := bigZero(), bigZero()
+	perimeter := bigZero()
+	lx, ly := bigZero(), bigZero()
+	var firstPt *Vector
+
+	for i := 0; i < len(g); i++ {
+		for j := 0; j < len(g[i]); j++ {
+			pts := ringPoints(g[i][j])
+			if len(pts) == 0 {
+				continue
+			}
+			if firstPt == nil {
+				firstPt = &pts[0]
+			}
+
+			// make the exterior ring count positive and interior rings negative
+			sign := newBigNumber(1)
+			ringArea2 := ringSignedArea2(pts)
+			if (j == 0) != ringArea2.isGreaterThanOrEqualTo(bigZero()) {
+				sign = newBigNumber(-1)
+			}
+			area2 = area2.plus(ringArea2.times(sign))
+
+			for k := 0; k < len(pts); k++ {
+				a := pts[k]
+				b := pts[(k+1)%len(pts)]
+				cross := crossProduct(a, b).times(sign)
+				cx = cx.plus(a.x.plus(b.x).times(cross))
+				cy = cy.plus(a.y.plus(b.y).times(cross))
+
+				l := length(Vector{x: b.x.minus(a.x), y: b.y.minus(a.y)})
+				perimeter = perimeter.plus(l)
+				lx = lx.plus(a.x.plus(b.x).times(l))
+				ly = ly.plus(a.y.plus(b.y).times(l))
+			}
+		}
+	}
+
+	if firstPt == nil {
+		return nil
+	}
+	if !area2.isZero() {
+		area6 := area2.times(newBigNumber(3))
+		return []float64{cx.div(area6).number(), cy.div(area6).number()}
+	}
+	if !perimeter.isZero() {
+		twicePerimeter := perimeter.times(newBigNumber(2))
+		return []float64{lx.div(twicePerimeter).number(), ly.div(twicePerimeter).number()}
+	}
+	return []float64{firstPt.x.number(), firstPt.y.number()}
+}
+
+// SignedAreas returns the signed area of every ring of the geometry, per
+// polygon. Counter-clockwise rings are positive and clockwise rings negative.
+func (g Geom) SignedAreas() [][]float64 {
+	areas := make([][]float64, len(g))
+	for i := 0; i < len(g); i++ {
+		areas[i] = make([]float64, len(g[i]))
+		for j := 0; j < len(g[i]); j++ {
+			areas[i][j] = ringSignedArea2(ringPoints(g[i][j])).div(newBigNumber(2)).number()
+		}
+	}
+	return areas
+}
+
+// ringPoints converts a ring to vectors, dropping positions with missing
+// coordinates and the closing point if present.
+func ringPoints(ring [][]float64) []Vector {
+	pts := make([]Vector, 0, len(ring))
+	for i := 0; i < len(ring); i++ {
+		if len(ring[i]) < 2 {
+			continue
+		}
+		pts = append(pts, newVectorLit(ring[i][0], ring[i][1]))
+	}
+	if len(pts) > 1 {
+		first, last := pts[0], pts[len(pts)-1]
+		if first.x.equalTo(last.x) && first.y.equalTo(last.y) {
+			pts = pts[:len(pts)-1]
+		}
+	}
+	return pts
+}
+
+// ringSignedArea2 is twice the signed area of a ring (shoelace formula).
+func ringSignedArea2(pts []Vector) BigNumber {
+	area2 := bigZero()
+	for i := 0; i < len(pts); i++ {
+		area2 = area2.plus(crossProduct(pts[i], pts[(i+1)%len(pts)]))
+	}
+	return area2
+}
+
+// polyArea2 is twice the area of a polygon.
+func polyArea2(poly [][][]float64) BigNumber {
+	area2 := bigZero()
+	for j := 0; j < len(poly); j++ {
+		ringArea2 := ringSignedArea2(ringPoints(poly[j])).abs()
+		if j == 0 {
+			area2 = area2.plus(ringArea2)
+		} else {
+			area2 = area2.minus(ringArea2)
+		}
+	}
+	return area2
+}
+
+func ringLength(pts []Vector) BigNumber {
+	l := bigZero()
+	for i := 0; i < len(pts); i++ {
+		a := pts[i]
+		b := pts[(i+1)%len(pts)]
+		l = l.plus(length(Vector{x: b.x.minus(a.x), y: b.y.minus(a.y)}))
+	}
+	return l
+}
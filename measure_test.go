@@ -0,0 +1,97 @@
+package polygol
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeasureArea(t *testing.T) {
+	// square with a hole, orientation doesn't matter
+	geom := Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+	}}
+	expect(t, geom.Area() == 12)
+	geom = Geom{{
+		{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}},
+		{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}},
+	}}
+	expect(t, geom.Area() == 12)
+
+	// multiple polygons, unclosed ring
+	geom = Geom{
+		{{{0, 0}, {2, 0}, {2, 2}, {0, 2}}},
+		{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}},
+	}
+	expect(t, geom.Area() == 4.5)
+
+	// exact where float64 arithmetic isn't
+	geom = Geom{{{{0.1, 0.1}, {0.3, 0.1}, {0.3, 0.3}, {0.1, 0.3}, {0.1, 0.1}}}}
+	expect(t, geom.Area() == 0.04)
+
+	// empty
+	expect(t, Geom{}.Area() == 0)
+}
+
+func TestMeasureSignedAreas(t *testing.T) {
+	geom := Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+	}}
+	areas := geom.SignedAreas()
+	expect(t, len(areas) == 1 && len(areas[0]) == 2)
+	expect(t, areas[0][0] == 16)
+	expect(t, areas[0][1] == -4)
+}
+
+func TestMeasurePerimeter(t *testing.T) {
+	geom := Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+	}}
+	expect(t, geom.Perimeter() == 24)
+
+	geom = Geom{{{{0, 0}, {3, 0}, {0, 4}, {0, 0}}}}
+	expect(t, geom.Perimeter() == 12)
+
+	geom = Geom{{{{0, 0}, {1, 1}, {0, 1}, {0, 0}}}}
+	expect(t, math.Abs(geom.Perimeter()-(2+math.Sqrt2)) < NumberEPSILON)
+}
+
+func TestMeasureCentroid(t *testing.T) {
+	equalPt := func(a, b []float64) bool {
+		return len(a) == 2 && len(b) == 2 &&
+			math.Abs(a[0]-b[0]) < NumberEPSILON && math.Abs(a[1]-b[1]) < NumberEPSILON
+	}
+
+	// square
+	geom := Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}}
+	expect(t, equalPt(geom.Centroid(), []float64{2, 2}))
+
+	// clockwise square
+	geom = Geom{{{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}}}}
+	expect(t, equalPt(geom.Centroid(), []float64{2, 2}))
+
+	// square with an off-center hole
+	geom = Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{2, 0}, {2, 4}, {4, 4}, {4, 0}, {2, 0}},
+	}}
+	expect(t, equalPt(geom.Centroid(), []float64{1, 2}))
+
+	// two polygons
+	geom = Geom{
+		{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+		{{{4, 0}, {6, 0}, {6, 2}, {4, 2}, {4, 0}}},
+	}
+	expect(t, equalPt(geom.Centroid(), []float64{3, 1}))
+
+	// no area
+	geom = Geom{{{{0, 0}, {4, 0}, {0, 0}}}}
+	expect(t, equalPt(geom.Centroid(), []float64{2, 0}))
+	geom = Geom{{{{1, 1}, {1, 1}}}}
+	expect(t, equalPt(geom.Centroid(), []float64{1, 1}))
+
+	// empty
+	expect(t, Geom{}.Centroid() == nil)
+}
@@ -0,0 +1,229 @@
+package polygol
+
+import "sort"
+
+// The Minkowski sum of two polygons A and B, each of them connected, is
+//
+//	A ⊕ B = (A + b) ∪ (B + a₁) ∪ … ∪ (B + aₙ) ∪ (∂A ⊕ ∂B)
+//
+// for any position b of B and every vertex aᵢ of A: wherever B placed at a
+// point p overlaps A, either its boundary crosses the boundary of A, which
+// the convolution ∂A ⊕ ∂B of the edges covers as a parallelogram per pair
+// of edges, or one of them lies within the other. The union resolves the
+// overlapping pieces with the same non-zero classification as any other,
+// and holes come out wherever no piece covers them.
+//
+// When B is convex, A ⊕ B is A + b along with, for every edge of A, the
+// convex hull of B at both ends of the edge, which takes as many pieces as
+// A has edges rather than the product of the numbers of edges.
+//
+// The Minkowski difference A ⊖ B, the positions where B fits within A, is
+// what's left of the box of positions keeping B within the bounds of A once
+// the complement of A, within a box around A, is summed with B turned around
+// the origin.
+
+func (p *Polygol) MinkowskiSum(a, b Geom) (Geom, error) {
+	result, err := p.minkowskiSum(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return p.output(result, []Geom{a, b}), nil
+}
+
+func (p *Polygol) minkowskiSum(a, b Geom) (Geom, error) {
+	a, err := p.makeValid(a)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = p.makeValid(b); err != nil {
+		return nil, err
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return Geom{}, nil
+	}
+
+	pieces := Geom{}
+	for _, pa := range a {
+		for _, pb := range b {
+			if !isConvexPolygon(pb) && isConvexPolygon(pa) {
+				pa, pb = pb, pa
+			}
+			pieces = append(pieces, minkowskiPieces(pa, pb)...)
+		}
+	}
+	return newOperation("union").run(pieces)
+}
+
+func (p *Polygol) MinkowskiDifference(a, b Geom) (Geom, error) {
+	result, err := p.minkowskiDifference(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return p.output(result, []Geom{a, b}), nil
+}
+
+func (p *Polygol) minkowskiDifference(a, b Geom) (Geom, error) {
+	a, err := p.makeValid(a)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = p.makeValid(b); err != nil {
+		return nil, err
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return Geom{}, nil
+	}
+
+	// B only fits where it's within the bounds of A, so the complement of A
+	// only matters just around them
+	bounds, _ := geomBounds(a)
+	bBounds, _ := geomBounds(b)
+	fit := [4]float64{bounds[0] - bBounds[0], bounds[1] - bBounds[1], bounds[2] - bBounds[2], bounds[3] - bBounds[3]}
+	if fit[0] > fit[2] || fit[1] > fit[3] {
+		return Geom{}, nil
+	}
+	margin := 1.0
+	box := Geom{{{
+		{bounds[0] - margin, bounds[1] - margin}, {bounds[2] + margin, bounds[1] - margin},
+		{bounds[2] + margin, bounds[3] + margin}, {bounds[0] - margin, bounds[3] + margin},
+		{bounds[0] - margin, bounds[1] - margin},
+	}}}
+	complement, err := newOperation("difference").run(box, a)
+	if err != nil {
+		return nil, err
+	}
+
+	reflected := Geom{}
+	for _, poly := range b {
+		reflectedPoly := [][][]float64{}
+		for _, ring := range poly {
+			reflectedRing := make([][]float64, len(ring))
+			for i, pos := range ring {
+				reflectedRing[i] = []float64{-pos[0], -pos[1]}
+			}
+			reflectedPoly = append(reflectedPoly, reflectedRing)
+		}
+		reflected = append(reflected, reflectedPoly)
+	}
+
+	sum, err := p.minkowskiSum(complement, reflected)
+	if err != nil {
+		return nil, err
+	}
+	fitBox := Geom{{{
+		{fit[0], fit[1]}, {fit[2], fit[1]}, {fit[2], fit[3]}, {fit[0], fit[3]}, {fit[0], fit[1]},
+	}}}
+	return newOperation("difference").run(fitBox, sum)
+}
+
+// minkowskiPieces returns polygons whose union is the sum of two valid
+// polygons.
+func minkowskiPieces(pa, pb [][][]float64) Geom {
+	pieces := Geom{translatePolygon(pa, pb[0][0])}
+
+	if isConvexPolygon(pb) {
+		hull := pb[0][:len(pb[0])-1]
+		for _, ring := range pa {
+			for i := 0; i+1 < len(ring); i++ {
+				pts := append(translateRing(hull, ring[i]), translateRing(hull, ring[i+1])...)
+				pieces = append(pieces, [][][]float64{convexHull(pts)})
+			}
+		}
+		return pieces
+	}
+
+	for _, ring := range pa {
+		for i := 0; i+1 < len(ring); i++ {
+			pieces = append(pieces, translatePolygon(pb, ring[i]))
+		}
+	}
+	for _, ringA := range pa {
+		for i := 0; i+1 < len(ringA); i++ {
+			e0, e1 := ringA[i], ringA[i+1]
+			for _, ringB := range pb {
+				for j := 0; j+1 < len(ringB); j++ {
+					f0, f1 := ringB[j], ringB[j+1]
+					pieces = append(pieces, [][][]float64{{
+						addPositions(e0, f0), addPositions(e1, f0),
+						addPositions(e1, f1), addPositions(e0, f1),
+						addPositions(e0, f0),
+					}})
+				}
+			}
+		}
+	}
+	return pieces
+}
+
+// isConvexPolygon tells whether a valid polygon, whose exterior ring runs
+// counter-clockwise, has no holes and no right turn.
+func isConvexPolygon(poly [][][]float64) bool {
+	if len(poly) != 1 {
+		return false
+	}
+	ring := poly[0]
+	n := len(ring) - 1 // closed
+	for i := 0; i < n; i++ {
+		a, b, c := ring[i], ring[(i+1)%n], ring[(i+2)%n]
+		if (b[0]-a[0])*(c[1]-b[1])-(b[1]-a[1])*(c[0]-b[0]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func addPositions(a, b []float64) []float64 {
+	return []float64{a[0] + b[0], a[1] + b[1]}
+}
+
+func translateRing(ring [][]float64, by []float64) [][]float64 {
+	out := make([][]float64, len(ring))
+	for i, pos := range ring {
+		out[i] = addPositions(pos, by)
+	}
+	return out
+}
+
+func translatePolygon(poly [][][]float64, by []float64) [][][]float64 {
+	out := make([][][]float64, len(poly))
+	for i, ring := range poly {
+		out[i] = translateRing(ring, by)
+	}
+	return out
+}
+
+// convexHull returns the closed counter-clockwise convex hull of positions,
+// by the monotone chain.
+func convexHull(pts [][]float64) [][]float64 {
+	sorted := append([][]float64{}, pts...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i][0] != sorted[j][0] {
+			return sorted[i][0] < sorted[j][0]
+		}
+		return sorted[i][1] < sorted[j][1]
+	})
+	turn := func(o, a, b []float64) float64 {
+		return (a[0]-o[0])*(b[1]-o[1]) - (a[1]-o[1])*(b[0]-o[0])
+	}
+
+	hull := [][]float64{}
+	for _, pass := range []int{1, -1} {
+		start := len(hull)
+		for k := range sorted {
+			pos := sorted[k]
+			if pass == -1 {
+				pos = sorted[len(sorted)-1-k]
+			}
+			for len(hull) >= start+2 && turn(hull[len(hull)-2], hull[len(hull)-1], pos) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, pos)
+		}
+		// the last position of a chain starts the other one
+		hull = hull[:len(hull)-1]
+	}
+	if len(hull) == 0 {
+		return hull
+	}
+	return append(hull, hull[0])
+}
@@ -0,0 +1,89 @@
+package polygol
+
+import (
+	"math"
+	"testing"
+)
+
+func rect(x0, y0, x1, y1 float64) [][]float64 {
+	return [][]float64{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}, {x0, y0}}
+}
+
+func TestMinkowskiSum(t *testing.T) {
+	lShape := Geom{{{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 2}, {0, 2}, {0, 0}}}}
+	cases := []struct {
+		name string
+		a, b Geom
+		area float64
+	}{
+		{"squares", Geom{{rect(0, 0, 2, 2)}}, Geom{{rect(0, 0, 1, 1)}}, 9},
+		{"away from the origin", Geom{{rect(0, 0, 2, 2)}}, Geom{{rect(10, 10, 11, 11)}}, 9},
+		{"non-convex and convex", lShape, Geom{{rect(0, 0, 1, 1)}}, 8},
+		{"convex and non-convex", Geom{{rect(0, 0, 1, 1)}}, lShape, 8},
+		{"non-convex", lShape, lShape, 13},
+		{"hole shrinking", Geom{{rect(0, 0, 10, 10), rect(4, 4, 6, 6)}}, Geom{{rect(0, 0, 1, 1)}}, 120},
+		{"hole filled", Geom{{rect(0, 0, 10, 10), rect(4, 4, 6, 6)}}, Geom{{rect(0, 0, 3, 3)}}, 169},
+		{"hole and non-convex", Geom{{rect(0, 0, 10, 10), rect(4, 4, 6, 6)}}, lShape, 144 - 1}, // the notch of the L at the top right corner
+	}
+	for _, c := range cases {
+		result, err := MinkowskiSum(c.a, c.b)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if area := result.Area(); math.Abs(area-c.area) > 1e-9 {
+			t.Errorf("%s: expected area %v, got %v: %v", c.name, c.area, area, result)
+		}
+	}
+
+	result, err := MinkowskiSum(Geom{{rect(0, 0, 2, 2)}}, Geom{{rect(10, 10, 11, 11)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds, _ := geomBounds(result); bounds != [4]float64{10, 10, 13, 13} {
+		t.Errorf("expected the sum within (10, 10) and (13, 13), got %v", bounds)
+	}
+}
+
+func TestMinkowskiDifference(t *testing.T) {
+	result, err := MinkowskiDifference(Geom{{rect(0, 0, 10, 10)}}, Geom{{rect(-1, -1, 1, 1)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds, _ := geomBounds(result); bounds != [4]float64{1, 1, 9, 9} || len(result) != 1 || len(result[0]) != 1 {
+		t.Errorf("expected the square from (1, 1) to (9, 9), got %v", result)
+	}
+
+	// a kernel away from the origin moves the difference the other way
+	result, err = MinkowskiDifference(Geom{{rect(0, 0, 10, 10)}}, Geom{{rect(100, 100, 101, 101)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds, _ := geomBounds(result); bounds != [4]float64{-100, -100, -91, -91} || len(result) != 1 || len(result[0]) != 1 {
+		t.Errorf("expected the square from (-100, -100) to (-91, -91), got %v", result)
+	}
+	result, err = MinkowskiDifference(Geom{{rect(0, 0, 10, 10)}}, Geom{{rect(-30, 5, -28, 6)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds, _ := geomBounds(result); bounds != [4]float64{30, -5, 38, 4} || len(result) != 1 || len(result[0]) != 1 {
+		t.Errorf("expected the rectangle from (30, -5) to (38, 4), got %v", result)
+	}
+
+	// a kernel wider than the geometry fits nowhere
+	result, err = MinkowskiDifference(Geom{{rect(0, 0, 10, 10)}}, Geom{{rect(0, 0, 11, 1)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 0 {
+		t.Errorf("expected nothing, got %v", result)
+	}
+
+	// a square with a hole keeps room for the kernel around the hole only
+	result, err = MinkowskiDifference(Geom{{rect(0, 0, 10, 10), rect(4, 4, 6, 6)}}, Geom{{rect(0, 0, 1, 1)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if area := result.Area(); math.Abs(area-(81-9)) > 1e-9 {
+		t.Errorf("expected area 72, got %v: %v", area, result)
+	}
+}
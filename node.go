@@ -0,0 +1,130 @@
+package polygol
+
+import (
+	"fmt"
+	"sort"
+)
+
+// nodeLines passes the sweep line over the segments of lines, which splits
+// them wherever they cross or touch and merges them where they overlap,
+// like the edges of rings in the Boolean operations. Every segment of a
+// line gets a ringIn of its own, so that the segments left after the sweep
+// can be traced back to the lines they came from, and a line running back
+// over itself still shows up twice on the merged segment.
+func (o *operation) nodeLines(lines [][][]float64) (*sweepLine, map[*ringIn]int, error) {
+	mpi := &multiPolyIn{isSubject: true}
+	pi := &polyIn{multiPoly: mpi}
+	pi.exteriorRing = &ringIn{poly: pi, isExterior: true}
+	mpi.polys = []*polyIn{pi}
+
+	owners := make(map[*ringIn]int)
+	for i, line := range lines {
+		var prev *point
+		for _, pos := range line {
+			if len(pos) < 2 {
+				return nil, nil, fmt.Errorf(`input line is not a valid line (missing coordinates)`)
+			}
+			pt := o.rounder.roundFloat(pos[0], pos[1])
+			// skip repeated points
+			if prev != nil && pt.equal(*prev) {
+				continue
+			}
+			if prev != nil {
+				ri := &ringIn{poly: pi, isExterior: true}
+				owners[ri] = i
+				seg, err := o.newSegmentFromRing(prev, pt, ri)
+				if err != nil {
+					return nil, nil, err
+				}
+				pi.exteriorRing.segments = append(pi.exteriorRing.segments, seg)
+			}
+			prev = pt
+		}
+	}
+
+	sl, err := o.sweep([]*multiPolyIn{mpi})
+	if err != nil {
+		return nil, nil, err
+	}
+	return sl, owners, nil
+}
+
+// LineIntersection is a position where two lines meet, or where a line
+// meets itself other than between consecutive segments, including the ends
+// of a stretch it runs back over, in which case Line and Other are the same.
+type LineIntersection struct {
+	X, Y  float64
+	Line  int
+	Other int
+}
+
+func (p *Polygol) Node(lines [][][]float64) ([][][]float64, []LineIntersection, error) {
+	sl, owners, err := p.newOperation("node").nodeLines(lines)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// how many segment ends of every line are at every point, and the lines
+	// running back over themselves up to every point
+	ends := make(map[*point]map[int]int)
+	overlaps := make(map[*point]map[int]bool)
+	points := []*point{}
+	segments := [][][]float64{}
+	for _, seg := range sl.segments {
+		if seg.consumedBy != nil {
+			continue
+		}
+		segments = append(segments, segmentGeom(seg))
+
+		// overlapping segments were merged, those of one line as well
+		passes := make(map[int]int)
+		for _, ring := range seg.rings {
+			passes[owners[ring]]++
+		}
+		for _, pt := range []*point{seg.leftSE.point, seg.rightSE.point} {
+			if ends[pt] == nil {
+				ends[pt] = make(map[int]int)
+				overlaps[pt] = make(map[int]bool)
+				points = append(points, pt)
+			}
+			for line, count := range passes {
+				ends[pt][line] += count
+				if count > 1 {
+					overlaps[pt][line] = true
+				}
+			}
+		}
+	}
+
+	intersections := []LineIntersection{}
+	for _, pt := range points {
+		meeting := []int{}
+		for line, count := range ends[pt] {
+			meeting = append(meeting, line)
+			// a line passes through its positions once, so twice is a crossing
+			if count > 2 || overlaps[pt][line] {
+				intersections = append(intersections, LineIntersection{pt.x.number(), pt.y.number(), line, line})
+			}
+		}
+		sort.Ints(meeting)
+		for i := 0; i < len(meeting); i++ {
+			for j := i + 1; j < len(meeting); j++ {
+				intersections = append(intersections, LineIntersection{pt.x.number(), pt.y.number(), meeting[i], meeting[j]})
+			}
+		}
+	}
+	sort.SliceStable(intersections, func(i, j int) bool {
+		a, b := intersections[i], intersections[j]
+		if a.X != b.X {
+			return a.X < b.X
+		}
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Other < b.Other
+	})
+	return segments, intersections, nil
+}
@@ -0,0 +1,87 @@
+package polygol
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNode(t *testing.T) {
+	cases := []struct {
+		name          string
+		lines         [][][]float64
+		segments      int
+		intersections []LineIntersection
+	}{
+		{
+			"crossing",
+			[][][]float64{{{0, 0}, {2, 2}}, {{0, 2}, {2, 0}}},
+			4,
+			[]LineIntersection{{1, 1, 0, 1}},
+		},
+		{
+			"end to end",
+			[][][]float64{{{0, 0}, {1, 0}}, {{1, 0}, {1, 1}}},
+			2,
+			[]LineIntersection{{1, 0, 0, 1}},
+		},
+		{
+			"overlapping",
+			[][][]float64{{{0, 0}, {3, 0}}, {{1, 0}, {4, 0}}},
+			3,
+			[]LineIntersection{{1, 0, 0, 1}, {3, 0, 0, 1}},
+		},
+		{
+			"crossing itself",
+			[][][]float64{{{0, 0}, {2, 2}, {2, 0}, {0, 2}}},
+			5,
+			[]LineIntersection{{1, 1, 0, 0}},
+		},
+		{
+			"running back over itself",
+			[][][]float64{{{5, 0}, {5, 4}, {5, 1}}},
+			2,
+			[]LineIntersection{{5, 1, 0, 0}, {5, 4, 0, 0}},
+		},
+		{
+			"running over itself three times",
+			[][][]float64{{{0, 0}, {4, 0}, {1, 0}, {3, 0}}},
+			3,
+			[]LineIntersection{{1, 0, 0, 0}, {3, 0, 0, 0}, {4, 0, 0, 0}},
+		},
+		{
+			"overlapping itself and another line",
+			[][][]float64{{{0, 0}, {2, 0}, {1, 0}}, {{1, 0}, {2, 0}}},
+			2,
+			[]LineIntersection{{1, 0, 0, 0}, {1, 0, 0, 1}, {2, 0, 0, 0}, {2, 0, 0, 1}},
+		},
+		{
+			"apart",
+			[][][]float64{{{0, 0}, {1, 0}, {1, 1}}, {{3, 0}, {4, 0}}},
+			3,
+			[]LineIntersection{},
+		},
+	}
+	for _, c := range cases {
+		segments, intersections, err := Node(c.lines)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(segments) != c.segments {
+			t.Errorf("%s: expected %d segments, got %v", c.name, c.segments, segments)
+		}
+		if !reflect.DeepEqual(intersections, c.intersections) {
+			t.Errorf("%s: expected intersections %v, got %v", c.name, c.intersections, intersections)
+		}
+	}
+
+	// the segments are split at the crossing
+	segments, _, err := Node([][][]float64{{{0, 0}, {2, 2}}, {{0, 2}, {2, 0}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, seg := range segments {
+		if (seg[0][0] != 1 || seg[0][1] != 1) && (seg[1][0] != 1 || seg[1][1] != 1) {
+			t.Errorf("expected every segment to end at (1, 1), got %v", seg)
+		}
+	}
+}
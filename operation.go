@@ -39,8 +39,13 @@ func init() {
 type operation struct {
 	rounder       *ptRounder
 	opType        string
+	fillRule      FillRule
 	numMultiPolys int
 	segmentID     int
+	clipBbox      *Bbox
+	// leaving is called with each segment as it leaves the sweep line, and
+	// stops the sweep when it returns true
+	leaving func(seg *segment) bool
 }
 
 func newOperation(opType string) *operation {
@@ -78,21 +83,61 @@ func (o *operation) run(geom Geom, moreGeoms ...Geom) (Geom, error) {
 			}
 		}
 	// BBox optimization for intersection operation
-	// If we can find any pair of multipolygons whose bbox does not overlap,
-	// then the result will be empty.
+	// The result must lie within the overlap of the bboxes of all the
+	// multipolygons. Axis-aligned bboxes overlap pairwise iff they all share
+	// a common overlap, so a single pass finds it. If there is none, the
+	// result is empty. Otherwise clip every multipolygon to that overlap so
+	// we don't sweep over segments that can't contribute to the result.
 	case "intersection":
-		// TODO: this is O(n^2) in number of polygons. By sorting the bboxes,
-		//       it could be optimized to O(n * ln(n))
+		overlap := &multiPolys[0].bbox
+		for i := 1; i < len(multiPolys); i++ {
+			overlap = overlap.getBboxOverlap(multiPolys[i].bbox)
+			if overlap == nil {
+				return Geom{}, nil
+			}
+		}
+		needsClipping := false
 		for i := 0; i < len(multiPolys); i++ {
-			mpA := multiPolys[i]
-			for j := i + 1; j < len(multiPolys); j++ {
-				if mpA.bbox.getBboxOverlap(multiPolys[i].bbox) == nil {
-					return Geom{}, nil
-				}
+			bbox := multiPolys[i].bbox
+			if !overlap.isInBbox(bbox.ll) || !overlap.isInBbox(bbox.ur) {
+				needsClipping = true
+				break
 			}
 		}
+		if needsClipping {
+			o.rounder.reset()
+			o.clipBbox = overlap
+			multiPolys, err = o.geomsToMultiPolys(geom, moreGeoms)
+			o.clipBbox = nil
+			if err != nil {
+				return Geom{}, err
+			}
+		}
+	}
+
+	sweepLine, err := o.sweep(multiPolys)
+	if err != nil {
+		return nil, err
 	}
 
+	// Free some memory we don't need anymore.
+	o.rounder.reset()
+
+	// Collect and compile segments we're keeping into a multipolygon.
+	ringsOut, err := newRingOutFromSegments(sweepLine.segments)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newMultiPolyOut(ringsOut)
+
+	return result.getGeom(), nil
+}
+
+// sweep puts the segment endpoints of the multipolygons in a priority queue
+// and passes the sweep line over them, splitting segments at intersections.
+func (o *operation) sweep(multiPolys []*multiPolyIn) (*sweepLine, error) {
+
 	// Put segment endpoints in a priority queue.
 	// Should be sorted by x coordinate.
 	queue := splaytree.New(sweepEventCompare)
@@ -156,6 +201,13 @@ func (o *operation) run(geom Geom, moreGeoms ...Geom) (Geom, error) {
 			return nil, err
 		}
 
+		// the sides of a segment are settled once it leaves the sweep line
+		if o.leaving != nil && !evt.isLeft && evt.consumedBy == nil && evt.segment.consumedBy == nil {
+			if o.leaving(evt.segment) {
+				return sweepLine, nil
+			}
+		}
+
 		for i := 0; i < len(newEvents); i++ {
 			evt := newEvents[i]
 			if evt.consumedBy == nil {
@@ -167,18 +219,7 @@ func (o *operation) run(geom Geom, moreGeoms ...Geom) (Geom, error) {
 		i++
 	}
 
-	// Free some memory we don't need anymore.
-	o.rounder.reset()
-
-	// Collect and compile segments we're keeping into a multipolygon.
-	ringsOut, err := newRingOutFromSegments(sweepLine.segments)
-	if err != nil {
-		return nil, err
-	}
-
-	result := newMultiPolyOut(ringsOut)
-
-	return result.getGeom(), nil
+	return sweepLine, nil
 }
 
 func (o *operation) geomsToMultiPolys(geom Geom, moreGeoms []Geom) ([]*multiPolyIn, error) {
@@ -0,0 +1,13 @@
+module github.com/engelsjk/polygol/orbadapter
+
+go 1.18
+
+require (
+	github.com/engelsjk/polygol v0.0.0-20261019180601-0904faf55bee
+	github.com/paulmach/orb v0.12.0
+)
+
+require (
+	github.com/cockroachdb/apd/v3 v3.2.1 // indirect
+	github.com/engelsjk/splay-tree v0.0.1 // indirect
+)
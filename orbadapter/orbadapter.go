@@ -0,0 +1,105 @@
+// Package orbadapter converts between paulmach/orb geometries and polygol
+// geometries, and wraps the polygol operations to take and return orb
+// MultiPolygons.
+//
+// Results are always MultiPolygons, an empty result being an empty but non-nil
+// MultiPolygon, however many polygons they have.
+package orbadapter
+
+import (
+	"fmt"
+
+	"github.com/engelsjk/polygol"
+	"github.com/paulmach/orb"
+)
+
+// ToGeom converts an orb Polygon, MultiPolygon, Ring or Bound, or a Collection
+// of those, to a polygol geometry.
+func ToGeom(g orb.Geometry) (polygol.Geom, error) {
+	switch v := g.(type) {
+	case orb.Polygon:
+		return polygol.Geom{polygonToGeom(v)}, nil
+	case orb.MultiPolygon:
+		geom := make(polygol.Geom, len(v))
+		for i := range v {
+			geom[i] = polygonToGeom(v[i])
+		}
+		return geom, nil
+	case orb.Ring:
+		return polygol.Geom{polygonToGeom(orb.Polygon{v})}, nil
+	case orb.Bound:
+		return polygol.Geom{polygonToGeom(v.ToPolygon())}, nil
+	case orb.Collection:
+		geom := polygol.Geom{}
+		for i := range v {
+			polys, err := ToGeom(v[i])
+			if err != nil {
+				return nil, fmt.Errorf("geometry %d of collection: %w", i, err)
+			}
+			geom = append(geom, polys...)
+		}
+		return geom, nil
+	case nil:
+		return polygol.Geom{}, nil
+	}
+	return nil, fmt.Errorf("orb geometry type %s has no area", g.GeoJSONType())
+}
+
+func polygonToGeom(p orb.Polygon) [][][]float64 {
+	poly := make([][][]float64, len(p))
+	for i := range p { // rings
+		poly[i] = make([][]float64, len(p[i]))
+		for j := range p[i] { // points
+			poly[i][j] = []float64{p[i][j][0], p[i][j][1]}
+		}
+	}
+	return poly
+}
+
+// FromGeom converts a polygol geometry to an orb MultiPolygon.
+func FromGeom(geom polygol.Geom) orb.MultiPolygon {
+	mp := make(orb.MultiPolygon, len(geom))
+	for i := range geom { // polygons
+		mp[i] = make(orb.Polygon, len(geom[i]))
+		for j := range geom[i] { // rings
+			mp[i][j] = make(orb.Ring, len(geom[i][j]))
+			for k := range geom[i][j] { // points
+				pt := geom[i][j][k]
+				mp[i][j][k] = orb.Point{pt[0], pt[1]}
+			}
+		}
+	}
+	return mp
+}
+
+func Union(mp orb.MultiPolygon, more ...orb.MultiPolygon) (orb.MultiPolygon, error) {
+	return apply(polygol.Union, mp, more)
+}
+
+func Intersection(mp orb.MultiPolygon, more ...orb.MultiPolygon) (orb.MultiPolygon, error) {
+	return apply(polygol.Intersection, mp, more)
+}
+
+func Difference(mp orb.MultiPolygon, more ...orb.MultiPolygon) (orb.MultiPolygon, error) {
+	return apply(polygol.Difference, mp, more)
+}
+
+func XOR(mp orb.MultiPolygon, more ...orb.MultiPolygon) (orb.MultiPolygon, error) {
+	return apply(polygol.XOR, mp, more)
+}
+
+func apply(
+	op func(geom polygol.Geom, moreGeoms ...polygol.Geom) (polygol.Geom, error),
+	mp orb.MultiPolygon, more []orb.MultiPolygon,
+) (orb.MultiPolygon, error) {
+	geom, _ := ToGeom(mp)
+	moreGeoms := make([]polygol.Geom, len(more))
+	for i := range more {
+		moreGeoms[i], _ = ToGeom(more[i])
+	}
+	result, err := op(geom, moreGeoms...)
+	if err != nil {
+		return nil, err
+	}
+	return FromGeom(result), nil
+}
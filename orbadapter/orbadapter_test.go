@@ -0,0 +1,102 @@
+package orbadapter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/engelsjk/polygol"
+	"github.com/paulmach/orb"
+)
+
+var (
+	square = orb.Polygon{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}
+	other  = orb.Polygon{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}
+)
+
+func TestToGeom(t *testing.T) {
+	squareGeom := polygol.Geom{{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}}
+
+	testCases := []struct {
+		name string
+		g    orb.Geometry
+		geom polygol.Geom
+	}{
+		{"polygon", square, squareGeom},
+		{"multipolygon", orb.MultiPolygon{square}, squareGeom},
+		{"ring", square[0], squareGeom},
+		{"bound", orb.Bound{Min: orb.Point{0, 0}, Max: orb.Point{2, 2}}, polygol.Geom{{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}}},
+		{"collection", orb.Collection{square, orb.MultiPolygon{other}}, polygol.Geom{squareGeom[0], {{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}}},
+		{"nil", nil, polygol.Geom{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			geom, err := ToGeom(tc.g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(geom, tc.geom) {
+				t.Errorf("expected %v, got %v", tc.geom, geom)
+			}
+		})
+	}
+
+	if _, err := ToGeom(orb.LineString{{0, 0}, {1, 1}}); err == nil {
+		t.Error("expected an error for a line string")
+	}
+	if _, err := ToGeom(orb.Collection{square, orb.Point{0, 0}}); err == nil {
+		t.Error("expected an error for a collection with a point")
+	}
+}
+
+func TestFromGeom(t *testing.T) {
+	mp := orb.MultiPolygon{square, other}
+	geom, err := ToGeom(mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back := FromGeom(geom); !reflect.DeepEqual(back, mp) {
+		t.Errorf("expected %v, got %v", mp, back)
+	}
+	if empty := FromGeom(polygol.Geom{}); empty == nil || len(empty) != 0 {
+		t.Errorf("expected an empty multipolygon, got %#v", empty)
+	}
+}
+
+func TestOperations(t *testing.T) {
+	a := orb.MultiPolygon{square}
+	b := orb.MultiPolygon{other}
+
+	testCases := []struct {
+		name string
+		op   func(orb.MultiPolygon, ...orb.MultiPolygon) (orb.MultiPolygon, error)
+		area float64
+	}{
+		{"union", Union, 7},
+		{"intersection", Intersection, 1},
+		{"difference", Difference, 3},
+		{"xor", XOR, 6},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.op(a, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			geom, _ := ToGeom(result)
+			if area := geom.Area(); area != tc.area {
+				t.Errorf("expected area %v, got %v", tc.area, area)
+			}
+		})
+	}
+
+	// results are multipolygons even when empty
+	result, err := Intersection(a, orb.MultiPolygon{{{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result == nil || len(result) != 0 {
+		t.Errorf("expected an empty multipolygon, got %#v", result)
+	}
+}
@@ -0,0 +1,173 @@
+package polygol
+
+import "sort"
+
+// Orientation is the winding of the rings of output geometries.
+type Orientation int
+
+const (
+	// ExteriorCCW winds exterior rings counter-clockwise and interior rings
+	// clockwise, as GeoJSON (RFC 7946) does.
+	ExteriorCCW Orientation = iota
+	// ExteriorCW winds exterior rings clockwise and interior rings
+	// counter-clockwise, as Shapefiles do.
+	ExteriorCW
+	// PreserveInput winds rings like the first exterior ring of the inputs
+	// with an area, counter-clockwise if there is none. Every geometry of
+	// SimplifyCoverage and SnapCoverage is wound like its own input.
+	PreserveInput
+)
+
+// result runs a Boolean operation and applies the output options to it.
+func (p *Polygol) result(opType string, geom Geom, moreGeoms []Geom) (Geom, error) {
+	result, err := p.run(opType, geom, moreGeoms)
+	if err != nil {
+		return nil, err
+	}
+	return p.output(result, append([]Geom{geom}, moreGeoms...)), nil
+}
+
+// output applies the orientation, ordering and closing options to a
+// geometry built the way the operations do, exterior rings counter-clockwise
+// and all rings closed, from the given inputs. Operations building on one
+// another keep to that until the end.
+func (p *Polygol) output(geom Geom, inputs []Geom) Geom {
+	cw := p.Orientation == ExteriorCW
+	if p.Orientation == PreserveInput {
+		cw = isInputExteriorCW(inputs)
+	}
+	if geom == nil || (!cw && !p.OpenRings && !p.Canonical) {
+		return geom
+	}
+
+	out := make(Geom, len(geom))
+	for i, poly := range geom {
+		out[i] = make([][][]float64, len(poly))
+		for j, ring := range poly {
+			if cw {
+				ring = reversePositions(ring)
+			}
+			if p.Canonical {
+				ring = startAtSmallest(ring)
+			}
+			if p.OpenRings && len(ring) > 0 {
+				ring = ring[:len(ring)-1]
+			}
+			out[i][j] = ring
+		}
+		if p.Canonical && len(out[i]) > 1 {
+			holes := out[i][1:]
+			sort.SliceStable(holes, func(a, b int) bool {
+				return compareRings(holes[a], holes[b]) < 0
+			})
+		}
+	}
+	if p.Canonical {
+		sort.SliceStable(out, func(a, b int) bool {
+			return comparePolys(out[a], out[b]) < 0
+		})
+	}
+	return out
+}
+
+// outputCoverage applies the output options to geometries that keep the
+// rings of their inputs, one geometry for every input, first winding their
+// rings the way the operations build them.
+func (p *Polygol) outputCoverage(geoms, inputs []Geom) []Geom {
+	out := make([]Geom, len(geoms))
+	for i, geom := range geoms {
+		out[i] = p.output(windExteriorCCW(geom), inputs[i:i+1])
+	}
+	return out
+}
+
+// windExteriorCCW reverses the exterior rings of a geometry winding
+// clockwise and the interior rings winding counter-clockwise, leaving those
+// without an area alone.
+func windExteriorCCW(geom Geom) Geom {
+	out := make(Geom, len(geom))
+	for i, poly := range geom {
+		out[i] = make([][][]float64, len(poly))
+		for j, ring := range poly {
+			area2 := ringSignedArea2(ringPoints(ring))
+			if !area2.isZero() && area2.isLessThan(bigZero()) == (j == 0) {
+				ring = reversePositions(ring)
+			}
+			out[i][j] = ring
+		}
+	}
+	return out
+}
+
+// startAtSmallest rotates a closed ring to start at its smallest position,
+// and of those it passes more than once, at the one followed by the
+// smallest positions.
+func startAtSmallest(ring [][]float64) [][]float64 {
+	n := len(ring) - 1
+	if n < 1 {
+		return ring
+	}
+	rotated := func(start int) [][]float64 {
+		out := make([][]float64, 0, len(ring))
+		out = append(out, ring[start:n]...)
+		out = append(out, ring[:start]...)
+		return append(out, out[0])
+	}
+	best := rotated(0)
+	for i := 1; i < n; i++ {
+		if comparePositions(ring[i], best[0]) > 0 {
+			continue
+		}
+		if candidate := rotated(i); compareRings(candidate, best) < 0 {
+			best = candidate
+		}
+	}
+	return best
+}
+
+func comparePositions(a, b []float64) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+func compareRings(a, b [][]float64) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := comparePositions(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+func comparePolys(a, b [][][]float64) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareRings(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return len(a) - len(b)
+}
+
+// isInputExteriorCW tells whether the first exterior ring of the inputs with
+// an area winds clockwise.
+func isInputExteriorCW(inputs []Geom) bool {
+	for _, geom := range inputs {
+		for _, poly := range geom {
+			if len(poly) == 0 {
+				continue
+			}
+			area2 := ringSignedArea2(ringPoints(poly[0]))
+			if !area2.isZero() {
+				return area2.isLessThan(bigZero())
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package polygol
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOutputOrientation(t *testing.T) {
+	// a square with a hole, given clockwise with a counter-clockwise hole
+	cw := Geom{{reversePositions(rect(0, 0, 4, 4)), reversePositions(rect(1, 1, 2, 2))}}
+
+	testCases := []struct {
+		name        string
+		orientation Orientation
+		exteriorCW  bool
+	}{
+		{"exterior ccw", ExteriorCCW, false},
+		{"exterior cw", ExteriorCW, true},
+		{"preserve input", PreserveInput, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &Polygol{Orientation: tc.orientation}
+			result, err := p.Union(cw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			areas := result.SignedAreas()
+			if len(areas) != 1 || len(areas[0]) != 2 {
+				t.Fatalf("expected a polygon with a hole, got %v", result)
+			}
+			if (areas[0][0] < 0) != tc.exteriorCW || (areas[0][1] < 0) == tc.exteriorCW {
+				t.Errorf("expected the exterior clockwise %v and the hole the other way, got %v", tc.exteriorCW, result)
+			}
+		})
+	}
+
+	// counter-clockwise input keeps counter-clockwise output
+	p := &Polygol{Orientation: PreserveInput}
+	result, err := p.Buffer(Geom{{rect(0, 0, 1, 1)}}, 1, BufferOptions{Join: JoinMitre})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if areas := result.SignedAreas(); len(areas) != 1 || areas[0][0] <= 0 {
+		t.Errorf("expected a counter-clockwise buffer, got %v", result)
+	}
+}
+
+func TestOutputOpenRings(t *testing.T) {
+	p := &Polygol{OpenRings: true}
+	result, err := p.Intersection(Geom{{rect(0, 0, 2, 2)}}, Geom{{rect(1, 1, 3, 3)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Geom{{{{1, 1}, {2, 1}, {2, 2}, {1, 2}}}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	// open rings read back the same as closed ones
+	again, err := p.Union(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, expected) {
+		t.Errorf("expected %v, got %v", expected, again)
+	}
+}
+
+func TestOutputInternal(t *testing.T) {
+	// the operations building on others see canonical rings
+	p := &Polygol{Orientation: ExteriorCW, OpenRings: true}
+	diff, err := p.MinkowskiDifference(Geom{{rect(0, 0, 4, 4)}}, Geom{{rect(0, 0, 1, 1)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Geom{{{{0, 0}, {0, 3}, {3, 3}, {3, 0}}}}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("expected %v, got %v", expected, diff)
+	}
+
+	gaps, err := p.CoverageGaps([]Geom{{{rect(0, 0, 3, 3), rect(1, 1, 2, 2)}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if areas := gaps.SignedAreas(); len(areas) != 1 || areas[0][0] != -1 || len(gaps[0][0]) != 4 {
+		t.Errorf("expected a clockwise open unit square, got %v", gaps)
+	}
+}
+
+func TestOutputCoverage(t *testing.T) {
+	// a clockwise square with a hole next to a counter-clockwise square, kept
+	// as they are by a zero tolerance
+	coverage := []Geom{
+		{{reversePositions(rect(0, 0, 4, 4)), reversePositions(rect(1, 1, 2, 2))}},
+		{{rect(5, 0, 6, 1)}},
+	}
+
+	testCases := []struct {
+		name     string
+		p        *Polygol
+		expected []Geom
+	}{
+		{
+			"exterior ccw",
+			&Polygol{Canonical: true, OpenRings: true},
+			[]Geom{
+				{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}, {{1, 1}, {1, 2}, {2, 2}, {2, 1}}}},
+				{{{{5, 0}, {6, 0}, {6, 1}, {5, 1}}}},
+			},
+		},
+		{
+			"exterior cw",
+			&Polygol{Orientation: ExteriorCW, Canonical: true, OpenRings: true},
+			[]Geom{
+				{{{{0, 0}, {0, 4}, {4, 4}, {4, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 2}}}},
+				{{{{5, 0}, {5, 1}, {6, 1}, {6, 0}}}},
+			},
+		},
+		{
+			// every geometry like its own input
+			"preserve input",
+			&Polygol{Orientation: PreserveInput, Canonical: true, OpenRings: true},
+			[]Geom{
+				{{{{0, 0}, {0, 4}, {4, 4}, {4, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 2}}}},
+				{{{{5, 0}, {6, 0}, {6, 1}, {5, 1}}}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			simplified, err := tc.p.SimplifyCoverage(coverage, 0, DouglasPeucker)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(simplified, tc.expected) {
+				t.Errorf("simplify: expected %v, got %v", tc.expected, simplified)
+			}
+
+			for _, tolerance := range []float64{0, 0.1} {
+				snapped, err := tc.p.SnapCoverage(coverage, tolerance)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(snapped, tc.expected) {
+					t.Errorf("snap %v: expected %v, got %v", tolerance, tc.expected, snapped)
+				}
+			}
+		})
+	}
+
+	// closed counter-clockwise rings by default
+	simplified, err := Simplify(coverage[0], 0, Visvalingam)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if areas := simplified.SignedAreas(); len(areas) != 1 || areas[0][0] != 16 || areas[0][1] != -1 || len(simplified[0][0]) != 5 {
+		t.Errorf("expected a closed counter-clockwise square with a clockwise hole, got %v", simplified)
+	}
+}
+
+func TestOutputCanonical(t *testing.T) {
+	square := Geom{{rect(0, 0, 10, 10), rect(6, 6, 8, 8), rect(2, 2, 4, 4)}}
+	other := Geom{{rect(20, 0, 22, 2)}, {rect(12, 5, 14, 7)}}
+	// the same geometries with their rings started elsewhere and in other orders
+	squareAgain := Geom{{
+		{{10, 10}, {0, 10}, {0, 0}, {10, 0}, {10, 10}},
+		{{4, 4}, {4, 2}, {2, 2}, {2, 4}, {4, 4}},
+		{{8, 6}, {6, 6}, {6, 8}, {8, 8}, {8, 6}},
+	}}
+	otherAgain := Geom{{rect(12, 5, 14, 7)}, {rect(20, 0, 22, 2)}}
+
+	expected := Geom{
+		{
+			{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+			{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+			{{6, 6}, {6, 8}, {8, 8}, {8, 6}, {6, 6}},
+		},
+		{{{12, 5}, {14, 5}, {14, 7}, {12, 7}, {12, 5}}},
+		{{{20, 0}, {22, 0}, {22, 2}, {20, 2}, {20, 0}}},
+	}
+
+	p := &Polygol{Canonical: true}
+	for name, inputs := range map[string][]Geom{
+		"in order":   {square, other},
+		"reordered":  {otherAgain, squareAgain},
+		"one at all": {append(Geom{other[1], squareAgain[0]}, other[0])},
+	} {
+		result, err := p.Union(inputs[0], inputs[1:]...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, result)
+		}
+	}
+
+	// triangles meeting at their smallest position, the lower one swept first
+	lower := Geom{{{{0, 0}, {2, -2}, {2, -1}, {0, 0}}}}
+	upper := Geom{{{{0, 0}, {1, 1}, {0, 2}, {0, 0}}}}
+	result, err := p.Union(lower, upper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if touching := append(upper, lower...); !reflect.DeepEqual(result, touching) {
+		t.Errorf("expected %v, got %v", touching, result)
+	}
+}
+
+func TestStartAtSmallest(t *testing.T) {
+	// a ring passing its smallest position twice
+	ring := [][]float64{{0, 0}, {2, -1}, {2, 0}, {0, 0}, {1, 2}, {0, 2}, {0, 0}}
+	expected := [][]float64{{0, 0}, {1, 2}, {0, 2}, {0, 0}, {2, -1}, {2, 0}, {0, 0}}
+	if result := startAtSmallest(ring); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
@@ -0,0 +1,219 @@
+// Package overlay overlays GeoJSON feature collections with polygol, keeping
+// track of the features every output feature comes from. It lives apart from
+// the geojson package, which doesn't depend on polygol.
+package overlay
+
+import (
+	"fmt"
+
+	"github.com/engelsjk/polygol"
+	"github.com/engelsjk/polygol/geojson"
+)
+
+// Overlay operations, named like the polygol functions they build on.
+const (
+	Intersection = "intersection"
+	Union        = "union"
+	Difference   = "difference"
+	XOR          = "xor"
+)
+
+// Collections overlays the features of two feature collections, keeping track of
+// the features each output feature comes from:
+//
+//   - intersection: a feature for every pair of intersecting features, with
+//     the properties of both
+//   - difference: every feature of a less all features of b, with its own
+//     properties
+//   - xor: the difference of a and b followed by the difference of b and a
+//   - union: the intersection followed by the xor
+//
+// Where both features of a pair have a property of the same name, the one of
+// the feature of b is suffixed with "_2". Features of a collection may
+// overlap each other and features without a geometry are skipped.
+func Collections(a, b *geojson.FeatureCollection, op string) (*geojson.FeatureCollection, error) {
+	return CollectionsWith(polygol.New(), a, b, op)
+}
+
+// CollectionsWith is Collections with the options of p, such as its fill rule.
+func CollectionsWith(p *polygol.Polygol, a, b *geojson.FeatureCollection, op string) (*geojson.FeatureCollection, error) {
+	aLayer, err := newLayer(a)
+	if err != nil {
+		return nil, fmt.Errorf("first collection: %w", err)
+	}
+	bLayer, err := newLayer(b)
+	if err != nil {
+		return nil, fmt.Errorf("second collection: %w", err)
+	}
+
+	out := geojson.NewFeatureCollection()
+	switch op {
+	case Intersection:
+		err = overlayIntersection(p, out, aLayer, bLayer)
+	case Difference:
+		err = overlayDifference(p, out, aLayer, bLayer)
+	case XOR:
+		err = overlayXOR(p, out, aLayer, bLayer)
+	case Union:
+		err = overlayIntersection(p, out, aLayer, bLayer)
+		if err == nil {
+			err = overlayXOR(p, out, aLayer, bLayer)
+		}
+	default:
+		return nil, fmt.Errorf("unknown overlay operation %q", op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// A layer holds the features of a collection that have area, along
+// with their geometries and bboxes so that far apart features are never
+// clipped against each other.
+type layer struct {
+	features []*geojson.Feature
+	geoms    []polygol.Geom
+	bboxes   [][4]float64
+}
+
+func newLayer(fc *geojson.FeatureCollection) (*layer, error) {
+	l := &layer{}
+	if fc == nil {
+		return l, nil
+	}
+	for i := 0; i < len(fc.Features); i++ {
+		f := fc.Features[i]
+		if f == nil || f.Geometry == nil {
+			continue
+		}
+		geom, err := f.Geometry.Geom()
+		if err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+		bbox, ok := geomBbox(geom)
+		if !ok {
+			continue
+		}
+		l.features = append(l.features, f)
+		l.geoms = append(l.geoms, geom)
+		l.bboxes = append(l.bboxes, bbox)
+	}
+	return l, nil
+}
+
+// candidates returns the geometries of other whose bboxes overlap the bbox of
+// the i-th geometry of l, along with their indexes.
+func (l *layer) candidates(i int, other *layer) ([]polygol.Geom, []int) {
+	geoms := []polygol.Geom{}
+	indexes := []int{}
+	for j := 0; j < len(other.geoms); j++ {
+		if bboxesOverlap(l.bboxes[i], other.bboxes[j]) {
+			geoms = append(geoms, other.geoms[j])
+			indexes = append(indexes, j)
+		}
+	}
+	return geoms, indexes
+}
+
+func overlayIntersection(p *polygol.Polygol, out *geojson.FeatureCollection, a, b *layer) error {
+	for i := 0; i < len(a.geoms); i++ {
+		geoms, indexes := a.candidates(i, b)
+		for k := 0; k < len(geoms); k++ {
+			result, err := p.Intersection(a.geoms[i], geoms[k])
+			if err != nil {
+				return err
+			}
+			if len(result) == 0 {
+				continue
+			}
+			j := indexes[k]
+			out.AddFeature(newFeature(result, a.features[i].Properties, b.features[j].Properties))
+		}
+	}
+	return nil
+}
+
+func overlayDifference(p *polygol.Polygol, out *geojson.FeatureCollection, a, b *layer) error {
+	for i := 0; i < len(a.geoms); i++ {
+		geoms, _ := a.candidates(i, b)
+		result, err := p.Difference(a.geoms[i], geoms...)
+		if err != nil {
+			return err
+		}
+		if len(result) == 0 {
+			continue
+		}
+		out.AddFeature(newFeature(result, a.features[i].Properties, nil))
+	}
+	return nil
+}
+
+func overlayXOR(p *polygol.Polygol, out *geojson.FeatureCollection, a, b *layer) error {
+	if err := overlayDifference(p, out, a, b); err != nil {
+		return err
+	}
+	return overlayDifference(p, out, b, a)
+}
+
+func newFeature(geom polygol.Geom, aProps, bProps map[string]any) *geojson.Feature {
+	var geometry *geojson.Geometry
+	if len(geom) == 1 {
+		geometry = geojson.NewPolygonGeometry(geom[0])
+	} else {
+		geometry = geojson.NewMultiPolygonGeometry(geom)
+	}
+	f := geojson.NewFeature(geometry)
+	for k, v := range aProps {
+		f.Properties[k] = v
+	}
+	for k, v := range bProps {
+		key := k
+		for {
+			if _, ok := f.Properties[key]; !ok {
+				break
+			}
+			key += "_2"
+		}
+		f.Properties[key] = v
+	}
+	return f
+}
+
+// geomBbox returns the bbox of a geometry as minX, minY, maxX, maxY, or false
+// if it has no positions.
+func geomBbox(geom polygol.Geom) ([4]float64, bool) {
+	var bbox [4]float64
+	found := false
+	for _, poly := range geom {
+		for _, ring := range poly {
+			for _, pos := range ring {
+				if len(pos) < 2 {
+					continue
+				}
+				if !found {
+					bbox = [4]float64{pos[0], pos[1], pos[0], pos[1]}
+					found = true
+					continue
+				}
+				if pos[0] < bbox[0] {
+					bbox[0] = pos[0]
+				}
+				if pos[1] < bbox[1] {
+					bbox[1] = pos[1]
+				}
+				if pos[0] > bbox[2] {
+					bbox[2] = pos[0]
+				}
+				if pos[1] > bbox[3] {
+					bbox[3] = pos[1]
+				}
+			}
+		}
+	}
+	return bbox, found
+}
+
+func bboxesOverlap(a, b [4]float64) bool {
+	return a[0] <= b[2] && b[0] <= a[2] && a[1] <= b[3] && b[1] <= a[3]
+}
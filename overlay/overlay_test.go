@@ -0,0 +1,104 @@
+package overlay
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/engelsjk/polygol"
+	"github.com/engelsjk/polygol/geojson"
+)
+
+func squareFeature(x, y, size float64, props map[string]any) *geojson.Feature {
+	f := geojson.NewFeature(geojson.NewPolygonGeometry([][][]float64{{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}, {x, y}}}))
+	f.Properties = props
+	return f
+}
+
+func TestCollections(t *testing.T) {
+	a := geojson.NewFeatureCollection().
+		AddFeature(squareFeature(0, 0, 2, map[string]any{"name": "a1"})).
+		AddFeature(squareFeature(10, 10, 2, map[string]any{"name": "a2"})).
+		AddFeature(&geojson.Feature{Type: geojson.TypeFeature})
+	b := geojson.NewFeatureCollection().
+		AddFeature(squareFeature(1, 1, 2, map[string]any{"name": "b1", "kind": "x"})).
+		AddFeature(squareFeature(2, 0, 1, map[string]any{"name": "b2"}))
+
+	type piece struct {
+		props map[string]any
+		area  float64
+	}
+	a1b1 := piece{map[string]any{"name": "a1", "name_2": "b1", "kind": "x"}, 1}
+	a1 := piece{map[string]any{"name": "a1"}, 3}
+	a2 := piece{map[string]any{"name": "a2"}, 4}
+	b1 := piece{map[string]any{"name": "b1", "kind": "x"}, 3}
+	b2 := piece{map[string]any{"name": "b2"}, 1}
+
+	testCases := []struct {
+		op     string
+		pieces []piece
+	}{
+		// a1 and b2 only touch
+		{Intersection, []piece{a1b1}},
+		{Difference, []piece{a1, a2}},
+		{XOR, []piece{a1, a2, b1, b2}},
+		{Union, []piece{a1b1, a1, a2, b1, b2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.op, func(t *testing.T) {
+			fc, err := Collections(a, b, tc.op)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(fc.Features) != len(tc.pieces) {
+				t.Fatalf("expected %d features, got %d", len(tc.pieces), len(fc.Features))
+			}
+			for i, f := range fc.Features {
+				if !reflect.DeepEqual(f.Properties, tc.pieces[i].props) {
+					t.Errorf("feature %d: expected properties %v, got %v", i, tc.pieces[i].props, f.Properties)
+				}
+				geom, err := f.Geometry.Geom()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if area := polygol.Geom(geom).Area(); area != tc.pieces[i].area {
+					t.Errorf("feature %d: expected area %v, got %v", i, tc.pieces[i].area, area)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionsErrors(t *testing.T) {
+	a := geojson.NewFeatureCollection().AddFeature(squareFeature(0, 0, 1, nil))
+	b := geojson.NewFeatureCollection().AddFeature(geojson.NewFeature(&geojson.Geometry{Type: geojson.TypePoint, Point: []float64{0, 0}}))
+
+	if _, err := Collections(a, a, "clip"); err == nil {
+		t.Error("expected an error for an unknown operation")
+	}
+	if _, err := Collections(a, b, Intersection); err == nil {
+		t.Error("expected an error for a point feature")
+	}
+}
+
+func TestCollectionsWith(t *testing.T) {
+	// overlapping polygons, whose overlap is left out under the even-odd rule
+	overlapping := geojson.NewFeature(geojson.NewMultiPolygonGeometry([][][][]float64{
+		{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+		{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}},
+	}))
+	a := geojson.NewFeatureCollection().AddFeature(overlapping)
+	b := geojson.NewFeatureCollection().AddFeature(squareFeature(0, 0, 4, nil))
+
+	fc, err := CollectionsWith(&polygol.Polygol{FillRule: polygol.EvenOdd}, a, b, Intersection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	geom, err := fc.Features[0].Geometry.Geom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if area := polygol.Geom(geom).Area(); area != 6 {
+		t.Errorf("expected area 6, got %v", area)
+	}
+}
@@ -1,27 +1,177 @@
 package polygol
 
+import "fmt"
+
 type Geom [][][][]float64
 
-type Polygol struct{}
+// FillRule decides which parts of the plane the rings of an input
+// multipolygon enclose.
+type FillRule int
+
+const (
+	// NonZero fills the area inside the exterior ring of any polygon and not
+	// inside one of the interior rings of that same polygon, whatever the
+	// ring orientation or the number of times a ring winds around the area.
+	NonZero FillRule = iota
+	// EvenOdd fills the area that is enclosed by an odd number of rings of
+	// the multipolygon, whether exterior or interior.
+	EvenOdd
+)
+
+type Polygol struct {
+	// FillRule used to interpret the input geometries, NonZero by default.
+	FillRule FillRule
+	// Antimeridian reads coordinates as longitude and latitude in degrees for
+	// the Boolean operations and repairs, with rings that may cross ±180° or
+	// wind around a pole. See antimeridian.go for the details.
+	Antimeridian bool
+	// Edges of the rings of the Boolean operations and repairs, Planar by
+	// default. See spherical.go for GreatCircle edges, which have no use for
+	// Antimeridian.
+	Edges Edges
+	// Orientation of the rings of the geometries built by the operations,
+	// ExteriorCCW by default. See output.go.
+	Orientation Orientation
+	// Canonical starts every ring of the geometries built by the operations
+	// at its smallest position, x then y, and sorts holes and polygons by
+	// their positions, so that the same geometry always comes out the same.
+	Canonical bool
+	// OpenRings leaves the closing position, the same as the first, off the
+	// rings of the geometries built by the operations.
+	OpenRings bool
+}
 
 func New() *Polygol {
 	return &Polygol{}
 }
 
+func (p *Polygol) newOperation(opType string) *operation {
+	op := newOperation(opType)
+	op.fillRule = p.FillRule
+	return op
+}
+
+// run runs a Boolean operation, on the sphere or first splitting the inputs
+// at the antimeridian if need be.
+func (p *Polygol) run(opType string, geom Geom, moreGeoms []Geom) (Geom, error) {
+	if p.Edges == GreatCircle {
+		return p.runSpherical(opType, geom, moreGeoms)
+	}
+	if p.Antimeridian {
+		return p.runAntimeridian(opType, geom, moreGeoms)
+	}
+	return p.newOperation(opType).run(geom, moreGeoms...)
+}
+
 func (p *Polygol) Union(geom Geom, moreGeoms ...Geom) (Geom, error) {
-	return newOperation("union").run(geom, moreGeoms...)
+	return p.result("union", geom, moreGeoms)
 }
 
 func (p *Polygol) Intersection(geom Geom, moreGeoms ...Geom) (Geom, error) {
-	return newOperation("intersection").run(geom, moreGeoms...)
+	return p.result("intersection", geom, moreGeoms)
 }
 
 func (p *Polygol) Difference(geom Geom, moreGeoms ...Geom) (Geom, error) {
-	return newOperation("difference").run(geom, moreGeoms...)
+	return p.result("difference", geom, moreGeoms)
 }
 
 func (p *Polygol) XOR(geom Geom, moreGeoms ...Geom) (Geom, error) {
-	return newOperation("xor").run(geom, moreGeoms...)
+	return p.result("xor", geom, moreGeoms)
+}
+
+func (p *Polygol) Validate(geom Geom) ([]ValidationIssue, error) {
+	return newValidator().validate(geom)
+}
+
+func (p *Polygol) Dissolve(geom Geom) (Geom, error) {
+	if len(geom) == 0 {
+		return Geom{}, nil
+	}
+	polys := splitPolys(geom)
+	if err := p.convertPolys(polys); err != nil {
+		return nil, err
+	}
+	return p.Union(polys[0], polys[1:]...)
+}
+
+func (p *Polygol) DissolveByKey(geom Geom, keys []string) (map[string]Geom, error) {
+	if len(keys) != len(geom) {
+		return nil, fmt.Errorf("got %d keys for %d polygons", len(keys), len(geom))
+	}
+	groups, order := groupPolys(geom, keys)
+	dissolved := make(map[string]Geom, len(groups))
+	for _, key := range order {
+		result, err := p.Dissolve(groups[key])
+		if err != nil {
+			return nil, fmt.Errorf("dissolving key %q: %w", key, err)
+		}
+		dissolved[key] = result
+	}
+	return dissolved, nil
+}
+
+// evaluate sweeps a and b until settled, then gives the answer of f.
+func (p *Polygol) evaluate(a, b Geom, f, settled func(pr *predicate) bool) (bool, error) {
+	pr, err := p.newOperation("predicate").newPredicate(a, b, settled)
+	if err != nil {
+		return false, err
+	}
+	return f(pr), nil
+}
+
+func (p *Polygol) Intersects(a, b Geom) (bool, error) {
+	return p.evaluate(a, b, (*predicate).intersects, (*predicate).intersects)
+}
+
+func (p *Polygol) Disjoint(a, b Geom) (bool, error) {
+	intersects, err := p.Intersects(a, b)
+	return !intersects && err == nil, err
+}
+
+func (p *Polygol) Contains(a, b Geom) (bool, error) {
+	return p.evaluate(a, b, (*predicate).contains, (*predicate).containsSettled)
+}
+
+func (p *Polygol) Within(a, b Geom) (bool, error) {
+	return p.Contains(b, a)
+}
+
+func (p *Polygol) Touches(a, b Geom) (bool, error) {
+	// the boundaries may meet further on, but once the interiors do, the
+	// answer is no
+	return p.evaluate(a, b, (*predicate).touches, func(pr *predicate) bool { return pr.both })
+}
+
+func (p *Polygol) Overlaps(a, b Geom) (bool, error) {
+	return p.evaluate(a, b, (*predicate).overlaps, (*predicate).overlaps)
+}
+
+func (p *Polygol) Relate(a, b Geom) (string, error) {
+	pr, err := p.newOperation("predicate").newPredicate(a, b, nil)
+	if err != nil {
+		return "", err
+	}
+	return pr.relate(), nil
+}
+
+func (p *Polygol) RelatePattern(a, b Geom, pattern string) (bool, error) {
+	matrix, err := p.Relate(a, b)
+	if err != nil {
+		return false, err
+	}
+	return relateMatches(matrix, pattern)
+}
+
+func (p *Polygol) Locate(geom Geom, x, y float64) (Location, error) {
+	locations, err := p.newOperation("locate").locate(geom, [][]float64{{x, y}})
+	if err != nil {
+		return Exterior, err
+	}
+	return locations[0], nil
+}
+
+func (p *Polygol) LocateAll(geom Geom, points [][]float64) ([]Location, error) {
+	return p.newOperation("locate").locate(geom, points)
 }
 
 func Union(geom Geom, moreGeoms ...Geom) (Geom, error) {
@@ -39,3 +189,177 @@ func Difference(geom Geom, moreGeoms ...Geom) (Geom, error) {
 func XOR(geom Geom, moreGeoms ...Geom) (Geom, error) {
 	return New().XOR(geom, moreGeoms...)
 }
+
+// Buffer grows a geometry by a positive distance or shrinks it by a negative
+// one, with the joins of the options at its corners. See buffer.go for how
+// the union of the offset edges makes the buffer.
+func Buffer(geom Geom, distance float64, opts BufferOptions) (Geom, error) {
+	return New().Buffer(geom, distance, opts)
+}
+
+// MinkowskiSum returns the positions of a plus those of b, the area b sweeps
+// as its origin moves over a. See minkowski.go for how the convolution of
+// their edges makes the sum.
+func MinkowskiSum(a, b Geom) (Geom, error) {
+	return New().MinkowskiSum(a, b)
+}
+
+// MinkowskiDifference returns the positions where b, moved there, lies
+// within a.
+func MinkowskiDifference(a, b Geom) (Geom, error) {
+	return New().MinkowskiDifference(a, b)
+}
+
+// Simplify drops positions of a geometry within the tolerance, a distance
+// for DouglasPeucker and an area for Visvalingam, without making any of its
+// rings cross or touch. See simplify.go for how that is checked.
+func Simplify(geom Geom, tolerance float64, method SimplifyMethod) (Geom, error) {
+	return New().Simplify(geom, tolerance, method)
+}
+
+// SimplifyCoverage simplifies geometries sharing boundaries together, so
+// that the boundaries stay shared. Neighbours must have the same positions
+// along their shared boundaries.
+func SimplifyCoverage(geoms []Geom, tolerance float64, method SimplifyMethod) ([]Geom, error) {
+	return New().SimplifyCoverage(geoms, tolerance, method)
+}
+
+// CoverageUnion merges the geometries of a coverage, which share boundaries
+// without overlapping, dropping the edges they share before the sweep. See
+// coverage.go for how that differs from a Union of them. Overlapping
+// geometries are an error.
+func CoverageUnion(coverage []Geom) (Geom, error) {
+	return New().CoverageUnion(coverage)
+}
+
+// CoverageOverlaps returns the areas covered by more than one geometry of a
+// coverage.
+func CoverageOverlaps(coverage []Geom) (Geom, error) {
+	return New().CoverageOverlaps(coverage)
+}
+
+// CoverageGaps returns the areas enclosed by a coverage that none of its
+// geometries cover, as polygons. Holes meant to be there are gaps too.
+func CoverageGaps(coverage []Geom) (Geom, error) {
+	return New().CoverageGaps(coverage)
+}
+
+// SnapCoverage moves the positions of the geometries of a coverage onto the
+// positions and edges of their neighbours within the tolerance, so that
+// boundaries that nearly coincide are shared exactly.
+func SnapCoverage(coverage []Geom, tolerance float64) ([]Geom, error) {
+	return New().SnapCoverage(coverage, tolerance)
+}
+
+// Polygonize builds the polygons enclosed by lines, after splitting them
+// wherever they cross. It also returns the dangles, segments with an end no
+// other segment meets, and the cuts, segments with the same polygon or the
+// outside on both sides, neither of which bound a polygon. See
+// polygonize.go for how the faces are walked.
+func Polygonize(lines [][][]float64) (Geom, [][][]float64, [][][]float64, error) {
+	return New().Polygonize(lines)
+}
+
+// Node splits lines wherever they cross, touch or overlap, returning the
+// segments they are split into, overlapping ones only once, and the
+// positions where lines meet.
+func Node(lines [][][]float64) ([][][]float64, []LineIntersection, error) {
+	return New().Node(lines)
+}
+
+// Validate reports the OGC validity problems of a geometry, along with rings
+// that don't follow the RFC 7946 orientation. Ring intersections and nesting
+// are found with a single pass of the sweep line. A valid geometry has no
+// issues. An error means the sweep failed and the geometry wasn't checked.
+func Validate(geom Geom) ([]ValidationIssue, error) {
+	return New().Validate(geom)
+}
+
+// MakeValid repairs a geometry into a valid MultiPolygon under the NonZero
+// fill rule, which keeps all of the area covered by the input. Use a Polygol
+// with the EvenOdd fill rule for the alternative. See Polygol.MakeValid for
+// the details of the repair.
+func MakeValid(geom Geom) (Geom, error) {
+	return New().MakeValid(geom)
+}
+
+// Dissolve merges the polygons of a multipolygon that overlap or share edges.
+// Each polygon is an input of its own, so the fill rule applies to every
+// polygon separately before they are merged, and an invalid polygon is an
+// error wherever it comes.
+func Dissolve(geom Geom) (Geom, error) {
+	return New().Dissolve(geom)
+}
+
+// DissolveByKey groups the polygons of a multipolygon by the key given for
+// each of them and dissolves every group, returning one Geom per key.
+func DissolveByKey(geom Geom, keys []string) (map[string]Geom, error) {
+	return New().DissolveByKey(geom, keys)
+}
+
+// Intersects reports whether two geometries share any point, interior or
+// boundary. Like the other predicates below, it runs the sweep only until the
+// answer is settled, e.g. up to the first point both share, and doesn't build
+// any output geometry. An invalid geometry on either side is an error.
+func Intersects(a, b Geom) (bool, error) {
+	return New().Intersects(a, b)
+}
+
+// Disjoint reports whether two geometries share no point at all.
+func Disjoint(a, b Geom) (bool, error) {
+	return New().Disjoint(a, b)
+}
+
+// Contains reports whether b has area and no part of b lies outside of a.
+func Contains(a, b Geom) (bool, error) {
+	return New().Contains(a, b)
+}
+
+// Within reports whether a has area and no part of a lies outside of b.
+func Within(a, b Geom) (bool, error) {
+	return New().Within(a, b)
+}
+
+// Touches reports whether the boundaries of two geometries share a point but
+// their interiors don't.
+func Touches(a, b Geom) (bool, error) {
+	return New().Touches(a, b)
+}
+
+// Overlaps reports whether two geometries share some area while each also
+// has area outside of the other.
+func Overlaps(a, b Geom) (bool, error) {
+	return New().Overlaps(a, b)
+}
+
+// Relate returns the DE-9IM matrix of two geometries: nine dimensions (F, 0,
+// 1 or 2) of the intersections of the interior, boundary and exterior of a
+// with those of b, row by row.
+func Relate(a, b Geom) (string, error) {
+	return New().Relate(a, b)
+}
+
+// RelatePattern reports whether the DE-9IM matrix of two geometries matches
+// a pattern such as "T*F**F***", where T matches any dimension and * matches
+// anything.
+func RelatePattern(a, b Geom, pattern string) (bool, error) {
+	return New().RelatePattern(a, b, pattern)
+}
+
+// RelateMatches reports whether a DE-9IM matrix as returned by Relate matches
+// a pattern.
+func RelateMatches(matrix, pattern string) (bool, error) {
+	return relateMatches(matrix, pattern)
+}
+
+// Locate tells whether a point is in the interior, on the boundary or in the
+// exterior of a geometry, deciding the way the clipping operations do.
+func Locate(geom Geom, x, y float64) (Location, error) {
+	return New().Locate(geom, x, y)
+}
+
+// LocateAll locates many points in a geometry at once, in a single sweep
+// over the geometry and the points.
+func LocateAll(geom Geom, points [][]float64) ([]Location, error) {
+	return New().LocateAll(geom, points)
+}
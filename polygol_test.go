@@ -78,3 +78,28 @@ func unmarshalFeatureOrFeatureCollection(b []byte) []*geojson.Feature {
 	}
 	return fc.Features
 }
+
+func TestIntersectionBboxOverlap(t *testing.T) {
+	a := Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}}
+	b := Geom{{{{3, 3}, {8, 3}, {8, 8}, {3, 8}, {3, 3}}}}
+	c := Geom{{{{6, 0}, {8, 0}, {8, 2}, {6, 2}, {6, 0}}}}
+
+	// each pair of bboxes overlaps except for a & c
+	result, err := Intersection(a, b, c)
+	terr(t, err)
+	expect(t, len(result) == 0)
+
+	result, err = Intersection(c, b, a)
+	terr(t, err)
+	expect(t, len(result) == 0)
+
+	// only the bbox overlap of the inputs is swept
+	d := Geom{
+		{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, {{1, 1}, {1, 2}, {2, 2}, {2, 1}, {1, 1}}},
+		{{{20, 20}, {30, 20}, {30, 30}, {20, 30}, {20, 20}}},
+	}
+	e := Geom{{{{8, 8}, {12, 8}, {12, 12}, {8, 12}, {8, 8}}}}
+	result, err = Intersection(d, e)
+	terr(t, err)
+	expect(t, equalMultiPoly(result, Geom{{{{8, 8}, {10, 8}, {10, 10}, {8, 10}, {8, 8}}}}))
+}
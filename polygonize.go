@@ -0,0 +1,262 @@
+package polygol
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Polygonize builds the polygons enclosed by lines. The lines are noded by
+// the sweep line, and the segments left over make up a planar graph.
+//
+// Dangles, the segments with an end no other segment meets, can't enclose
+// anything and are pruned first, along with what becomes a dangle once they
+// are gone. The faces of the graph are then walked like output rings: every
+// segment is walked once in each direction, taking the left-most segment
+// wherever the walk meets others, so that every walk goes round the face on
+// its left. Bounded faces are walked counter-clockwise, while the outer
+// boundary of every connected part of the graph is walked clockwise. Cuts,
+// the segments with the same face on both sides, don't bound anything
+// either, and the faces are walked again without them.
+//
+// The clockwise boundaries are holes of the face around them, found like
+// the enclosing ring of an output ring: the face above the segment below
+// its left-most point, unless that's the boundary of another part of the
+// graph, whose enclosing face is its too.
+
+// halfEdge is a segment walked from its left event to its right one, or
+// back.
+type halfEdge struct {
+	seg     *segment
+	forward bool
+}
+
+func (h halfEdge) from() *sweepEvent {
+	if h.forward {
+		return h.seg.leftSE
+	}
+	return h.seg.rightSE
+}
+
+func (h halfEdge) to() *sweepEvent {
+	return h.from().otherSE
+}
+
+type face struct {
+	edges     []halfEdge
+	isBounded bool
+	enclosing *face
+	// enclosed is set once enclosing is known
+	enclosed bool
+	holes    []*face
+}
+
+func (f *face) getGeom() [][]float64 {
+	ring := [][]float64{}
+	for _, h := range f.edges {
+		pt := h.from().point
+		ring = append(ring, []float64{pt.x.number(), pt.y.number()})
+	}
+	return append(ring, ring[0])
+}
+
+type polygonizer struct {
+	segments []*segment
+	live     map[*segment]bool
+	faces    []*face
+	faceOf   map[halfEdge]*face
+}
+
+func (p *Polygol) Polygonize(lines [][][]float64) (Geom, [][][]float64, [][][]float64, error) {
+	sl, _, err := p.newOperation("polygonize").nodeLines(lines)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	pz := &polygonizer{live: make(map[*segment]bool)}
+	for _, seg := range sl.segments {
+		if seg.consumedBy == nil {
+			pz.segments = append(pz.segments, seg)
+			pz.live[seg] = true
+		}
+	}
+
+	dangles := [][][]float64{}
+	for _, seg := range pz.pruneDangles() {
+		dangles = append(dangles, segmentGeom(seg))
+	}
+
+	if err := pz.walkFaces(); err != nil {
+		return nil, nil, nil, err
+	}
+	cuts := [][][]float64{}
+	for _, seg := range pz.segments {
+		if pz.live[seg] && pz.faceOf[halfEdge{seg, true}] == pz.faceOf[halfEdge{seg, false}] {
+			delete(pz.live, seg)
+			cuts = append(cuts, segmentGeom(seg))
+		}
+	}
+	if len(cuts) > 0 {
+		if err := pz.walkFaces(); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// only the segments left bound faces
+	for _, seg := range pz.segments {
+		seg.forceInResult, seg.inResult = true, pz.live[seg]
+	}
+	for _, f := range pz.faces {
+		if !f.isBounded {
+			if enclosing := pz.getEnclosingFace(f); enclosing != nil {
+				enclosing.holes = append(enclosing.holes, f)
+			}
+		}
+	}
+
+	geom := Geom{}
+	for _, f := range pz.faces {
+		if !f.isBounded {
+			continue
+		}
+		poly := [][][]float64{f.getGeom()}
+		for _, hole := range f.holes {
+			poly = append(poly, hole.getGeom())
+		}
+		geom = append(geom, poly)
+	}
+	return p.output(geom, nil), dangles, cuts, nil
+}
+
+func segmentGeom(seg *segment) [][]float64 {
+	l, r := seg.leftSE.point, seg.rightSE.point
+	return [][]float64{{l.x.number(), l.y.number()}, {r.x.number(), r.y.number()}}
+}
+
+// pruneDangles removes the segments with an end no other segment meets,
+// until there are none left, and returns them.
+func (pz *polygonizer) pruneDangles() []*segment {
+	degrees := make(map[*point]int)
+	for _, seg := range pz.segments {
+		degrees[seg.leftSE.point]++
+		degrees[seg.rightSE.point]++
+	}
+	ends := []*point{}
+	for _, seg := range pz.segments {
+		for _, pt := range []*point{seg.leftSE.point, seg.rightSE.point} {
+			if degrees[pt] == 1 {
+				ends = append(ends, pt)
+			}
+		}
+	}
+
+	dangles := []*segment{}
+	for len(ends) > 0 {
+		pt := ends[len(ends)-1]
+		ends = ends[:len(ends)-1]
+		for _, evt := range pt.events {
+			seg := evt.segment
+			if !pz.live[seg] {
+				continue
+			}
+			delete(pz.live, seg)
+			dangles = append(dangles, seg)
+			degrees[pt]--
+			other := evt.otherSE.point
+			if degrees[other]--; degrees[other] == 1 {
+				ends = append(ends, other)
+			}
+			break
+		}
+	}
+	return dangles
+}
+
+// walkFaces walks every live segment in both directions, keeping to the
+// left-most segment at every point.
+func (pz *polygonizer) walkFaces() error {
+	pz.faces = []*face{}
+	pz.faceOf = make(map[halfEdge]*face)
+	for _, seg := range pz.segments {
+		for _, forward := range []bool{true, false} {
+			start := halfEdge{seg, forward}
+			if !pz.live[seg] || pz.faceOf[start] != nil {
+				continue
+			}
+			f := &face{}
+			for h := start; ; {
+				pz.faceOf[h] = f
+				f.edges = append(f.edges, h)
+
+				event := h.to()
+				available := []*sweepEvent{}
+				for _, evt := range event.point.events {
+					if evt != event && pz.live[evt.segment] {
+						available = append(available, evt)
+					}
+				}
+				if len(available) == 0 {
+					pt := event.point
+					return fmt.Errorf("unable to complete face at [%f, %f]", pt.x.number(), pt.y.number())
+				}
+				comparator := event.getLeftMostComparator(h.from())
+				sort.SliceStable(available, func(i, j int) bool {
+					return comparator(available[i], available[j]) < 0
+				})
+				h = halfEdge{available[0].segment, available[0].isLeft}
+				if h == start {
+					break
+				}
+				if pz.faceOf[h] != nil {
+					pt := event.point
+					return fmt.Errorf("unable to complete face at [%f, %f]: segment walked twice", pt.x.number(), pt.y.number())
+				}
+			}
+
+			area2 := bigZero()
+			for _, h := range f.edges {
+				area2 = area2.plus(crossProduct(h.from().point.Vector, h.to().point.Vector))
+			}
+			f.isBounded = area2.isGreaterThan(bigZero())
+			pz.faces = append(pz.faces, f)
+		}
+	}
+	return nil
+}
+
+// getEnclosingFace returns the bounded face around the outer boundary of a
+// connected part of the graph, if any.
+func (pz *polygonizer) getEnclosingFace(f *face) *face {
+	if f.enclosed {
+		return f.enclosing
+	}
+	f.enclosed = true
+
+	// the lowest segment leaving the left-most point
+	var leftMost *point
+	for _, h := range f.edges {
+		if pt := h.from().point; leftMost == nil || sweepEventComparePoints(pt, leftMost) < 0 {
+			leftMost = pt
+		}
+	}
+	var lowest *segment
+	for _, h := range f.edges {
+		if h.seg.leftSE.point == leftMost && (lowest == nil || segmentCompare(h.seg, lowest) < 0) {
+			lowest = h.seg
+		}
+	}
+
+	for prevSeg := lowest.prevInResult(); prevSeg != nil; prevSeg = prevSeg.prevInResult() {
+		// the face above a segment is on the left of its forward walk
+		above := pz.faceOf[halfEdge{prevSeg, true}]
+		if above == f {
+			continue
+		}
+		if above.isBounded {
+			f.enclosing = above
+		} else {
+			f.enclosing = pz.getEnclosingFace(above)
+		}
+		break
+	}
+	return f.enclosing
+}
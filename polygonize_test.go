@@ -0,0 +1,87 @@
+package polygol
+
+import (
+	"testing"
+)
+
+func TestPolygonize(t *testing.T) {
+	// a noughts and crosses grid in a square
+	lines := [][][]float64{
+		rect(0, 0, 3, 3),
+		{{1, 0}, {1, 3}}, {{2, 0}, {2, 3}},
+		{{0, 1}, {3, 1}}, {{0, 2}, {3, 2}},
+	}
+	result, dangles, cuts, err := Polygonize(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 9 || len(dangles) != 0 || len(cuts) != 0 {
+		t.Fatalf("expected 9 squares, got %v, dangles %v, cuts %v", result, dangles, cuts)
+	}
+	for _, poly := range result {
+		if len(poly) != 1 || (Geom{poly}).Area() != 1 {
+			t.Errorf("expected a unit square, got %v", poly)
+		}
+		if issues := mustValidate(t, Geom{poly}); len(issues) > 0 {
+			t.Errorf("expected a valid polygon, got %v", issues)
+		}
+	}
+}
+
+func TestPolygonizeDanglesAndCuts(t *testing.T) {
+	lines := [][][]float64{
+		rect(0, 0, 4, 4),
+		// sticking out of the square and into it
+		{{4, 2}, {5, 2}, {6, 3}},
+		{{0, 2}, {1, 2}},
+		// a bridge to another square
+		{{10, 0}, {12, 0}, {12, 2}, {10, 2}, {10, 0}},
+		{{4, 1}, {10, 1}},
+	}
+	result, dangles, cuts, err := Polygonize(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result.Area() != 20 {
+		t.Errorf("expected the two squares, got %v", result)
+	}
+	if len(dangles) != 3 {
+		t.Errorf("expected 3 dangling segments, got %v", dangles)
+	}
+	if len(cuts) != 1 || cuts[0][0][0] != 4 || cuts[0][1][0] != 10 {
+		t.Errorf("expected the bridge as a cut, got %v", cuts)
+	}
+}
+
+func TestPolygonizeHoles(t *testing.T) {
+	for name, lines := range map[string][][][]float64{
+		"apart": {rect(0, 0, 10, 10), rect(4, 4, 6, 6)},
+		// the cut is dropped before the faces are walked again
+		"cut": {rect(0, 0, 10, 10), rect(4, 4, 6, 6), {{0, 5}, {4, 5}}},
+		// the inner square's boundary is below another part of the graph
+		"next to another part": {rect(0, 0, 10, 10), rect(4, 4, 6, 6), rect(4, 1, 6, 3)},
+	} {
+		result, _, _, err := Polygonize(lines)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the polygons share edges, so they're valid one by one
+		for _, poly := range result {
+			if issues := mustValidate(t, Geom{poly}); len(issues) > 0 {
+				t.Errorf("%s: expected a valid polygon, got %v", name, issues)
+			}
+		}
+		var outer [][][]float64
+		for _, poly := range result {
+			if poly[0][0][0] == 0 || poly[0][1][0] == 0 {
+				outer = poly
+			}
+		}
+		if outer == nil || len(outer) != len(result) {
+			t.Errorf("%s: expected the big square with a hole for every other polygon, got %v", name, result)
+		}
+		if area := result.Area(); area != 100 {
+			t.Errorf("%s: expected the polygons to cover the big square, got area %v", name, area)
+		}
+	}
+}
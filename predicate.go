@@ -0,0 +1,142 @@
+package polygol
+
+// A predicate runs the sweep over two multipolygons and classifies each
+// segment, as it leaves the sweep line, by which of the two inputs lie on
+// either side of it: by then every intersection along it has been found and
+// the segments below it are done, so its sides are settled. What the
+// segments show is gathered as they go, and the sweep stops as soon as it
+// settles the question asked, e.g. at the first segment with both inputs on
+// one side for Intersects. No output rings are ever built.
+
+type predicate struct {
+	a, b *multiPolyIn
+	// bboxes don't overlap, so there's no need to sweep at all
+	disjoint bool
+	// segments that have left the sweep line so far
+	segments []*segment
+	// some area is covered by both inputs, by a only or by b only
+	both, onlyA, onlyB bool
+	// the boundaries of the inputs share a point
+	boundaries bool
+	// endpoints of the segments of either boundary
+	aPoints, bPoints map[string]bool
+}
+
+// side tells which of the two inputs cover one side of a segment.
+type side struct {
+	a, b bool
+}
+
+// newPredicate sweeps a and b until settled reports that the answer is
+// known, or else to the end. A nil settled always sweeps to the end.
+func (o *operation) newPredicate(a, b Geom, settled func(pr *predicate) bool) (*predicate, error) {
+
+	o.rounder.reset()
+
+	// unlike the clipping geometries of the Boolean operations, an invalid b
+	// isn't just skipped, as the answer would be about a instead
+	mpA, err := o.newMultiPolyIn(a, true)
+	if err != nil {
+		return nil, err
+	}
+	mpB, err := o.newMultiPolyIn(b, false)
+	if err != nil {
+		return nil, err
+	}
+	multiPolys := []*multiPolyIn{mpA, mpB}
+	pr := &predicate{
+		a:       mpA,
+		b:       mpB,
+		aPoints: make(map[string]bool),
+		bPoints: make(map[string]bool),
+	}
+
+	if pr.a.bbox.getBboxOverlap(pr.b.bbox) == nil {
+		pr.disjoint = true
+		return pr, nil
+	}
+	if settled != nil && settled(pr) {
+		return pr, nil
+	}
+
+	o.leaving = func(seg *segment) bool {
+		pr.add(seg)
+		return settled != nil && settled(pr)
+	}
+	if _, err := o.sweep(multiPolys); err != nil {
+		return nil, err
+	}
+	o.rounder.reset()
+
+	return pr, nil
+}
+
+// add gathers what a segment leaving the sweep line shows.
+func (pr *predicate) add(seg *segment) {
+	pr.segments = append(pr.segments, seg)
+
+	before, after := pr.sides(seg)
+	for _, sd := range []side{before, after} {
+		pr.both = pr.both || (sd.a && sd.b)
+		pr.onlyA = pr.onlyA || (sd.a && !sd.b)
+		pr.onlyB = pr.onlyB || (sd.b && !sd.a)
+	}
+
+	// crossing boundaries were split by the sweep, so they share the
+	// endpoint of a segment
+	isBoundaryA := before.a != after.a
+	isBoundaryB := before.b != after.b
+	if isBoundaryA && isBoundaryB {
+		pr.boundaries = true
+	}
+	for _, pt := range []*point{seg.leftSE.point, seg.rightSE.point} {
+		key := pt.String()
+		if isBoundaryA {
+			pr.aPoints[key] = true
+			pr.boundaries = pr.boundaries || pr.bPoints[key]
+		}
+		if isBoundaryB {
+			pr.bPoints[key] = true
+			pr.boundaries = pr.boundaries || pr.aPoints[key]
+		}
+	}
+}
+
+func (pr *predicate) sides(s *segment) (side, side) {
+	mpsBefore := s.beforeState().multiPolys
+	mpsAfter := s.afterState().multiPolys
+	before := side{a: pr.a.indexOf(mpsBefore) != -1, b: pr.b.indexOf(mpsBefore) != -1}
+	after := side{a: pr.a.indexOf(mpsAfter) != -1, b: pr.b.indexOf(mpsAfter) != -1}
+	return before, after
+}
+
+func (pr *predicate) intersects() bool {
+	return pr.both || pr.boundaries
+}
+
+func (pr *predicate) touches() bool {
+	return !pr.both && pr.boundaries
+}
+
+// outsideBbox reports whether b reaches outside of the bbox of a, and so
+// can't be within a.
+func (pr *predicate) outsideBbox() bool {
+	return !pr.a.bbox.isInBbox(pr.b.bbox.ll) || !pr.a.bbox.isInBbox(pr.b.bbox.ur)
+}
+
+// contains reports whether b has area and none of it is outside of a.
+func (pr *predicate) contains() bool {
+	return !pr.disjoint && !pr.outsideBbox() && !pr.onlyB && pr.both
+}
+
+// containsSettled reports whether the answer to contains is known to be no,
+// whatever segments are still to come.
+func (pr *predicate) containsSettled() bool {
+	return pr.outsideBbox() || pr.onlyB
+}
+
+// overlaps reports whether the inputs share area and both have area the
+// other doesn't cover.
+func (pr *predicate) overlaps() bool {
+	return pr.both && pr.onlyA && pr.onlyB
+}
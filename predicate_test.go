@@ -0,0 +1,187 @@
+package polygol
+
+import (
+	"testing"
+)
+
+func TestPredicates(t *testing.T) {
+	square := Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}}
+	squareWithHole := Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+	}}
+
+	testCases := []struct {
+		name                                            string
+		a, b                                            Geom
+		intersects, contains, within, touches, overlaps bool
+	}{
+		{
+			name: "disjoint bboxes",
+			a:    square,
+			b:    Geom{{{{5, 5}, {6, 5}, {6, 6}, {5, 6}, {5, 5}}}},
+		},
+		{
+			name: "disjoint, overlapping bboxes",
+			a:    Geom{{{{0, 0}, {4, 0}, {0, 4}, {0, 0}}}},
+			b:    Geom{{{{4, 4}, {4, 1}, {1, 4}, {4, 4}}}},
+		},
+		{
+			name: "in a hole",
+			a:    squareWithHole,
+			b:    Geom{{{{1.5, 1.5}, {2.5, 1.5}, {2.5, 2.5}, {1.5, 2.5}, {1.5, 1.5}}}},
+		},
+		{
+			name:       "touching edges",
+			a:          square,
+			b:          Geom{{{{4, 1}, {6, 1}, {6, 3}, {4, 3}, {4, 1}}}},
+			intersects: true,
+			touches:    true,
+		},
+		{
+			name:       "touching corners",
+			a:          square,
+			b:          Geom{{{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}}}},
+			intersects: true,
+			touches:    true,
+		},
+		{
+			name:       "vertex touching an edge",
+			a:          square,
+			b:          Geom{{{{4, 2}, {6, 0}, {6, 4}, {4, 2}}}},
+			intersects: true,
+			touches:    true,
+		},
+		{
+			name:       "filling a hole",
+			a:          squareWithHole,
+			b:          Geom{{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}},
+			intersects: true,
+			touches:    true,
+		},
+		{
+			name:       "overlapping",
+			a:          square,
+			b:          Geom{{{{2, 2}, {6, 2}, {6, 6}, {2, 6}, {2, 2}}}},
+			intersects: true,
+			overlaps:   true,
+		},
+		{
+			name:       "containing",
+			a:          square,
+			b:          Geom{{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}},
+			intersects: true,
+			contains:   true,
+		},
+		{
+			name:       "containing, sharing edges",
+			a:          square,
+			b:          Geom{{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}},
+			intersects: true,
+			contains:   true,
+		},
+		{
+			name:       "equal",
+			a:          square,
+			b:          Geom{{{{4, 4}, {0, 4}, {0, 0}, {4, 0}, {4, 4}}}},
+			intersects: true,
+			contains:   true,
+			within:     true,
+		},
+		{
+			name:       "within",
+			a:          Geom{{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}},
+			b:          square,
+			intersects: true,
+			within:     true,
+		},
+		{
+			name:       "overlapping the hole",
+			a:          squareWithHole,
+			b:          Geom{{{{0.5, 0.5}, {3.5, 0.5}, {3.5, 3.5}, {0.5, 3.5}, {0.5, 0.5}}}},
+			intersects: true,
+			overlaps:   true,
+		},
+		{
+			name: "empty",
+			a:    square,
+			b:    Geom{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, reverse := range []bool{false, true} {
+				a, b := tc.a, tc.b
+				contains, within := tc.contains, tc.within
+				if reverse {
+					a, b = b, a
+					contains, within = within, contains
+				}
+
+				intersects, err := Intersects(a, b)
+				terr(t, err)
+				expect(t, intersects == tc.intersects)
+
+				disjoint, err := Disjoint(a, b)
+				terr(t, err)
+				expect(t, disjoint == !tc.intersects)
+
+				result, err := Contains(a, b)
+				terr(t, err)
+				expect(t, result == contains)
+
+				result, err = Within(a, b)
+				terr(t, err)
+				expect(t, result == within)
+
+				result, err = Touches(a, b)
+				terr(t, err)
+				expect(t, result == tc.touches)
+
+				result, err = Overlaps(a, b)
+				terr(t, err)
+				expect(t, result == tc.overlaps)
+			}
+		})
+	}
+}
+
+func TestPredicatesInvalidInput(t *testing.T) {
+	square := Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}}
+	invalid := Geom{{{}}}
+
+	// an invalid geometry is an error on either side, not a disjoint one
+	for _, args := range [][2]Geom{{square, invalid}, {invalid, square}} {
+		if _, err := Intersects(args[0], args[1]); err == nil {
+			t.Errorf("expected an error from Intersects(%v, %v)", args[0], args[1])
+		}
+		if _, err := Relate(args[0], args[1]); err == nil {
+			t.Errorf("expected an error from Relate(%v, %v)", args[0], args[1])
+		}
+	}
+}
+
+func TestPredicatesStopEarly(t *testing.T) {
+	// a overlaps the far left of b, whose segments go on to the right
+	a := Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}}
+	b := Geom{{{{2, 2}, {6, 2}, {6, 3}, {8, 3}, {8, 2}, {100, 2}, {100, 6}, {2, 6}, {2, 2}}}}
+
+	full, err := newOperation("predicate").newPredicate(a, b, nil)
+	terr(t, err)
+	expect(t, full.intersects())
+
+	pr, err := newOperation("predicate").newPredicate(a, b, (*predicate).intersects)
+	terr(t, err)
+	expect(t, pr.intersects())
+	if len(pr.segments) >= len(full.segments) {
+		t.Errorf("expected the sweep to stop early, got %d of %d segments", len(pr.segments), len(full.segments))
+	}
+
+	// and contains stops once b reaches outside of a, or skips the sweep if
+	// its bbox does
+	pr, err = newOperation("predicate").newPredicate(a, b, (*predicate).containsSettled)
+	terr(t, err)
+	expect(t, !pr.contains())
+	expect(t, len(pr.segments) == 0)
+}
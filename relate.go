@@ -0,0 +1,126 @@
+package polygol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// relate computes the DE-9IM matrix of a and b, a string of nine dimensions
+// for the intersections of the interior, boundary and exterior of a (rows)
+// with those of b (columns). From the segments of the full sweep:
+//
+//   - interiors and exteriors meet in areas, found on a side of a segment
+//   - a boundary meets an interior or exterior along a segment of the
+//     boundary with the other input on both or neither of its sides
+//   - boundaries meet along a segment of both boundaries, or else at the
+//     endpoints of segments of either boundary
+func (pr *predicate) relate() string {
+
+	matrix := []byte("FFFFFFFF2")
+	const (
+		ii, ib, ie = 0, 1, 2
+		bi, bb, be = 3, 4, 5
+		ei, eb, ee = 6, 7, 8
+	)
+
+	if pr.disjoint {
+		if len(pr.a.polys) > 0 {
+			matrix[ie], matrix[be] = '2', '1'
+		}
+		if pr.b != nil && len(pr.b.polys) > 0 {
+			matrix[ei], matrix[eb] = '2', '1'
+		}
+		return string(matrix)
+	}
+
+	aPoints := make(map[string]bool)
+	bSegments := []*segment{}
+
+	for i := 0; i < len(pr.segments); i++ {
+		seg := pr.segments[i]
+		before, after := pr.sides(seg)
+
+		for _, sd := range []side{before, after} {
+			if sd.a && sd.b {
+				matrix[ii] = '2'
+			}
+			if sd.a && !sd.b {
+				matrix[ie] = '2'
+			}
+			if sd.b && !sd.a {
+				matrix[ei] = '2'
+			}
+		}
+
+		isBoundaryA := before.a != after.a
+		isBoundaryB := before.b != after.b
+
+		if isBoundaryA && isBoundaryB {
+			matrix[bb] = '1'
+		}
+		if isBoundaryA && !isBoundaryB {
+			if before.b {
+				matrix[bi] = '1'
+			} else {
+				matrix[be] = '1'
+			}
+		}
+		if isBoundaryB && !isBoundaryA {
+			if before.a {
+				matrix[ib] = '1'
+			} else {
+				matrix[eb] = '1'
+			}
+		}
+
+		if isBoundaryA {
+			aPoints[seg.leftSE.point.String()] = true
+			aPoints[seg.rightSE.point.String()] = true
+		}
+		if isBoundaryB {
+			bSegments = append(bSegments, seg)
+		}
+	}
+
+	if matrix[bb] == 'F' {
+		for i := 0; i < len(bSegments); i++ {
+			if aPoints[bSegments[i].leftSE.point.String()] || aPoints[bSegments[i].rightSE.point.String()] {
+				matrix[bb] = '0'
+				break
+			}
+		}
+	}
+
+	return string(matrix)
+}
+
+// relateMatches reports whether a DE-9IM matrix matches a pattern of nine
+// characters: a dimension (0, 1 or 2) or F to match exactly, T to match any
+// dimension and * to match anything.
+func relateMatches(matrix, pattern string) (bool, error) {
+	if len(matrix) != 9 {
+		return false, fmt.Errorf("DE-9IM matrix must have 9 characters, got %q", matrix)
+	}
+	if len(pattern) != 9 {
+		return false, fmt.Errorf("DE-9IM pattern must have 9 characters, got %q", pattern)
+	}
+	for i := 0; i < 9; i++ {
+		if !strings.ContainsRune("*TtFf012", rune(pattern[i])) {
+			return false, fmt.Errorf("invalid character %q in DE-9IM pattern %q", pattern[i], pattern)
+		}
+	}
+	for i := 0; i < 9; i++ {
+		switch p := pattern[i]; p {
+		case '*':
+		case 'T', 't':
+			if matrix[i] == 'F' {
+				return false, nil
+			}
+		default:
+			if matrix[i] != p && !(p == 'f' && matrix[i] == 'F') {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
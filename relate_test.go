@@ -0,0 +1,74 @@
+package polygol
+
+import (
+	"testing"
+)
+
+func TestRelate(t *testing.T) {
+	square := Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}}
+	squareWithHole := Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+	}}
+
+	testCases := []struct {
+		name   string
+		a, b   Geom
+		matrix string
+	}{
+		{"disjoint bboxes", square, Geom{{{{5, 5}, {6, 5}, {6, 6}, {5, 6}, {5, 5}}}}, "FF2FF1212"},
+		{"disjoint", Geom{{{{0, 0}, {4, 0}, {0, 4}, {0, 0}}}}, Geom{{{{4, 4}, {4, 1}, {1, 4}, {4, 4}}}}, "FF2FF1212"},
+		{"touching edges", square, Geom{{{{4, 1}, {6, 1}, {6, 3}, {4, 3}, {4, 1}}}}, "FF2F11212"},
+		{"touching corners", square, Geom{{{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}}}}, "FF2F01212"},
+		{"overlapping", square, Geom{{{{2, 2}, {6, 2}, {6, 6}, {2, 6}, {2, 2}}}}, "212101212"},
+		{"containing", square, Geom{{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}}, "212FF1FF2"},
+		{"containing, sharing edges", square, Geom{{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}}}, "212F11FF2"},
+		{"within", Geom{{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}}, square, "2FF1FF212"},
+		{"equal", square, Geom{{{{4, 4}, {0, 4}, {0, 0}, {4, 0}, {4, 4}}}}, "2FFF1FFF2"},
+		{"filling a hole", squareWithHole, Geom{{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}}, "FF2F112F2"},
+		{"in a hole", squareWithHole, Geom{{{{1.5, 1.5}, {2.5, 1.5}, {2.5, 2.5}, {1.5, 2.5}, {1.5, 1.5}}}}, "FF2FF1212"},
+		{"empty", square, Geom{}, "FF2FF1FF2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matrix, err := Relate(tc.a, tc.b)
+			terr(t, err)
+			if matrix != tc.matrix {
+				t.Errorf("expected %s, got %s", tc.matrix, matrix)
+			}
+		})
+	}
+}
+
+func TestRelatePattern(t *testing.T) {
+	square := Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}}
+	inner := Geom{{{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}}}}
+
+	// within
+	within, err := RelatePattern(inner, square, "T*F**F***")
+	terr(t, err)
+	expect(t, within)
+	within, err = RelatePattern(square, inner, "T*F**F***")
+	terr(t, err)
+	expect(t, !within)
+
+	// contains
+	contains, err := RelatePattern(square, inner, "T*****FF*")
+	terr(t, err)
+	expect(t, contains)
+
+	// exact dimensions
+	match, err := RelateMatches("212101212", "2121*1212")
+	terr(t, err)
+	expect(t, match)
+	match, err = RelateMatches("212101212", "212111212")
+	terr(t, err)
+	expect(t, !match)
+
+	// bad patterns
+	_, err = RelateMatches("212101212", "T*F")
+	expect(t, err != nil)
+	_, err = RelateMatches("212101212", "T*F**F**X")
+	expect(t, err != nil)
+}
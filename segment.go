@@ -575,6 +575,28 @@ func (s *segment) afterState() *state {
 		}
 	}
 
+	// under the even-odd rule, a multipoly is represented iff the sum of the
+	// windings of all its rings is odd, regardless of exterior and interior
+	if s.op.fillRule == EvenOdd {
+		windingsByMultiPoly := make(map[*multiPolyIn]int)
+		for i := 0; i < len(s.after.rings); i++ {
+			mp := s.after.rings[i].poly.multiPoly
+			if _, ok := windingsByMultiPoly[mp]; !ok {
+				s.after.multiPolys = append(s.after.multiPolys, mp)
+			}
+			windingsByMultiPoly[mp] += s.after.windings[i]
+		}
+		multiPolysAfter := []*multiPolyIn{}
+		for i := 0; i < len(s.after.multiPolys); i++ {
+			mp := s.after.multiPolys[i]
+			if windingsByMultiPoly[mp]%2 != 0 {
+				multiPolysAfter = append(multiPolysAfter, mp)
+			}
+		}
+		s.after.multiPolys = multiPolysAfter
+		return s.after
+	}
+
 	// calculate polysAfter
 	polysAfter := []*polyIn{}
 	polysExclude := []*polyIn{}
@@ -665,6 +687,12 @@ func (s *segment) isInResult() bool {
 			return len(mps) == 1 && mps[0].isSubject
 		}
 		s.inResult = isJustSubject(mpsBefore) != isJustSubject(mpsAfter)
+	case "overlap":
+		// OVERLAP - included iff:
+		//  * on one side of us there are 2 or more multipolys represented
+		//    with poly interiors AND
+		//  * on the other side there are fewer.
+		s.inResult = (len(mpsBefore) >= 2) != (len(mpsAfter) >= 2)
 	default:
 		fmt.Printf("Unrecognized operation type found %s", s.op.opType)
 	}
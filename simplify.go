@@ -0,0 +1,702 @@
+package polygol
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Simplification works on chains rather than rings. Every ring is cut at its
+// junctions, the positions where the rings passing through don't all come
+// from and go to the same neighbours, and the pieces in between are chains,
+// shared by every ring running along them in either direction. A ring
+// without junctions is a closed chain of its own, starting at its smallest
+// position so that it's shared by identical rings too. Simplifying every
+// chain once and putting the rings back together keeps shared boundaries
+// shared, as long as neighbouring rings have the same positions along them.
+//
+// The chains are then checked together with the sweep line: wherever two
+// simplified edges cross, overlap or touch other than at a junction, or a
+// simplified edge cuts a kept position of another chain off from the side
+// it was on, the chains at fault are simplified again with half their
+// tolerance, until there's no conflict left or they're back to their input.
+
+// SimplifyMethod is the algorithm used by Simplify.
+type SimplifyMethod int
+
+const (
+	// DouglasPeucker keeps the positions farther than the tolerance from
+	// the simplified line.
+	DouglasPeucker SimplifyMethod = iota
+	// Visvalingam drops the positions whose triangle with their neighbours
+	// has an area below the tolerance, smallest first.
+	Visvalingam
+)
+
+// simplifyMaxHalvings is how many times a chain's tolerance is halved before
+// all of its positions are kept.
+const simplifyMaxHalvings = 30
+
+// chain is a run of ring positions from one junction to the next. Closed
+// chains start and end at the same position.
+type chain struct {
+	pts       [][]float64
+	free      bool
+	tolerance float64
+	halvings  int
+	keep      []bool
+}
+
+// chainUse is a chain along a ring, possibly backwards.
+type chainUse struct {
+	chain    int
+	reversed bool
+}
+
+type xy [2]float64
+
+func toXY(pos []float64) xy {
+	// -0 and 0 are the same position
+	return xy{pos[0] + 0, pos[1] + 0}
+}
+
+func (a xy) less(b xy) bool {
+	return a[0] < b[0] || (a[0] == b[0] && a[1] < b[1])
+}
+
+func (p *Polygol) Simplify(geom Geom, tolerance float64, method SimplifyMethod) (Geom, error) {
+	geoms, err := p.SimplifyCoverage([]Geom{geom}, tolerance, method)
+	if err != nil {
+		return nil, err
+	}
+	return geoms[0], nil
+}
+
+func (p *Polygol) SimplifyCoverage(geoms []Geom, tolerance float64, method SimplifyMethod) ([]Geom, error) {
+	if method != DouglasPeucker && method != Visvalingam {
+		return nil, fmt.Errorf("unknown simplification method %d", method)
+	}
+	if math.IsNaN(tolerance) || math.IsInf(tolerance, 0) || tolerance < 0 {
+		return nil, fmt.Errorf("invalid simplification tolerance %v", tolerance)
+	}
+	for _, geom := range geoms {
+		for _, poly := range geom {
+			for _, ring := range poly {
+				for _, pos := range ring {
+					if len(pos) < 2 {
+						return nil, fmt.Errorf(`input geometry is not a valid polygon or multipolygon (missing coordinates)`)
+					}
+				}
+			}
+		}
+	}
+
+	// distinct consecutive positions of every ring, without the closing one
+	rings := make([][][][][]float64, len(geoms))
+	for i, geom := range geoms {
+		rings[i] = make([][][][]float64, len(geom))
+		for j, poly := range geom {
+			rings[i][j] = make([][][]float64, len(poly))
+			for k, ring := range poly {
+				rings[i][j][k] = openRing(ring)
+			}
+		}
+	}
+
+	junctions := findJunctions(rings)
+	chains := []*chain{}
+	ids := make(map[string]int)
+	uses := make([][][][]chainUse, len(geoms))
+	for i := range rings {
+		uses[i] = make([][][]chainUse, len(rings[i]))
+		for j := range rings[i] {
+			uses[i][j] = make([][]chainUse, len(rings[i][j]))
+			for k, ring := range rings[i][j] {
+				if len(ring) < 3 {
+					continue
+				}
+				runs, free := cutRing(ring, junctions)
+				for _, run := range runs {
+					uses[i][j][k] = append(uses[i][j][k], addChain(&chains, ids, run, free, tolerance))
+				}
+			}
+		}
+	}
+
+	for _, c := range chains {
+		c.simplify(method)
+	}
+	for {
+		conflicts, err := findConflicts(chains)
+		if err != nil {
+			return nil, err
+		}
+		changed := false
+		for i := range conflicts {
+			c := chains[i]
+			if c.isKeepingAll() {
+				continue
+			}
+			c.halvings++
+			c.tolerance /= 2
+			c.simplify(method)
+			changed = true
+		}
+		if !changed {
+			break
+		}
+	}
+
+	out := make([]Geom, len(geoms))
+	for i, geom := range geoms {
+		out[i] = make(Geom, len(geom))
+		for j, poly := range geom {
+			out[i][j] = make([][][]float64, len(poly))
+			for k, ring := range poly {
+				if len(uses[i][j][k]) == 0 {
+					// too few positions to simplify
+					out[i][j][k] = copyRing(ring)
+					continue
+				}
+				out[i][j][k] = joinChains(chains, uses[i][j][k])
+			}
+		}
+	}
+	return p.outputCoverage(out, geoms), nil
+}
+
+// openRing returns the distinct consecutive positions of a ring, without
+// the closing one.
+func openRing(ring [][]float64) [][]float64 {
+	pts := [][]float64{}
+	for _, pos := range ring {
+		if len(pts) > 0 && toXY(pts[len(pts)-1]) == toXY(pos) {
+			continue
+		}
+		pts = append(pts, pos)
+	}
+	if n := len(pts); n > 1 && toXY(pts[0]) == toXY(pts[n-1]) {
+		pts = pts[:n-1]
+	}
+	return pts
+}
+
+func copyRing(ring [][]float64) [][]float64 {
+	out := make([][]float64, len(ring))
+	for i, pos := range ring {
+		out[i] = append([]float64{}, pos...)
+	}
+	return out
+}
+
+// findJunctions returns the positions that rings pass through between
+// different pairs of neighbours.
+func findJunctions(rings [][][][][]float64) map[xy]bool {
+	type neighbours struct{ a, b xy }
+	seen := make(map[xy]neighbours)
+	junctions := make(map[xy]bool)
+	for _, geom := range rings {
+		for _, poly := range geom {
+			for _, ring := range poly {
+				n := len(ring)
+				if n < 3 {
+					continue
+				}
+				for i := range ring {
+					pos := toXY(ring[i])
+					a, b := toXY(ring[(i+n-1)%n]), toXY(ring[(i+1)%n])
+					if b.less(a) {
+						a, b = b, a
+					}
+					if other, ok := seen[pos]; !ok {
+						seen[pos] = neighbours{a, b}
+					} else if other != (neighbours{a, b}) {
+						junctions[pos] = true
+					}
+				}
+			}
+		}
+	}
+	return junctions
+}
+
+// cutRing cuts an open ring into runs of positions between junctions, or
+// returns it closed and free if it has none.
+func cutRing(ring [][]float64, junctions map[xy]bool) (runs [][][]float64, free bool) {
+	n := len(ring)
+	start := -1
+	for i := range ring {
+		if junctions[toXY(ring[i])] {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return [][][]float64{append(append([][]float64{}, ring...), ring[0])}, true
+	}
+
+	run := [][]float64{ring[start]}
+	for k := 1; k <= n; k++ {
+		pos := ring[(start+k)%n]
+		run = append(run, pos)
+		if junctions[toXY(pos)] {
+			runs = append(runs, run)
+			run = [][]float64{pos}
+		}
+	}
+	return runs, false
+}
+
+// addChain finds the chain along a run of positions, in either direction,
+// or adds it. Free runs are rings without junctions, which may start
+// anywhere.
+func addChain(chains *[]*chain, ids map[string]int, run [][]float64, free bool, tolerance float64) chainUse {
+	if free {
+		// rings without junctions start at their smallest position
+		pts := run[:len(run)-1]
+		n := len(pts)
+		min := 0
+		for i := range pts {
+			if toXY(pts[i]).less(toXY(pts[min])) {
+				min = i
+			}
+		}
+		rotated := make([][]float64, 0, n+1)
+		for k := 0; k < n; k++ {
+			rotated = append(rotated, pts[(min+k)%n])
+		}
+		run = append(rotated, rotated[0])
+	}
+
+	key := chainKey(run, false)
+	if id, ok := ids[key]; ok {
+		return chainUse{chain: id}
+	}
+	if id, ok := ids[chainKey(run, true)]; ok {
+		return chainUse{chain: id, reversed: true}
+	}
+	if free {
+		// a free chain going the other way also starts at the smallest
+		// position
+		backwards := append([][]float64{run[0]}, reversePositions(run[1:len(run)-1])...)
+		backwards = append(backwards, run[0])
+		if id, ok := ids[chainKey(backwards, false)]; ok {
+			return chainUse{chain: id, reversed: true}
+		}
+	}
+
+	*chains = append(*chains, &chain{pts: run, free: free, tolerance: tolerance})
+	ids[key] = len(*chains) - 1
+	return chainUse{chain: len(*chains) - 1}
+}
+
+func reversePositions(pts [][]float64) [][]float64 {
+	out := make([][]float64, len(pts))
+	for i, pos := range pts {
+		out[len(pts)-1-i] = pos
+	}
+	return out
+}
+
+func chainKey(run [][]float64, reversed bool) string {
+	var sb strings.Builder
+	for i := range run {
+		pos := run[i]
+		if reversed {
+			pos = run[len(run)-1-i]
+		}
+		p := toXY(pos)
+		fmt.Fprintf(&sb, "%v %v;", p[0], p[1])
+	}
+	return sb.String()
+}
+
+// joinChains puts a ring back together from its simplified chains.
+func joinChains(chains []*chain, uses []chainUse) [][]float64 {
+	ring := [][]float64{}
+	for _, use := range uses {
+		pts := chains[use.chain].kept()
+		if use.reversed {
+			pts = reversePositions(pts)
+		}
+		if len(ring) > 0 {
+			pts = pts[1:]
+		}
+		for _, pos := range pts {
+			ring = append(ring, append([]float64{}, pos...))
+		}
+	}
+	return ring
+}
+
+func (c *chain) isClosed() bool {
+	return toXY(c.pts[0]) == toXY(c.pts[len(c.pts)-1])
+}
+
+func (c *chain) isKeepingAll() bool {
+	for _, keep := range c.keep {
+		if !keep {
+			return false
+		}
+	}
+	return true
+}
+
+// kept returns the positions kept by the simplification. A free chain that
+// dropped its start is closed at its first kept position instead.
+func (c *chain) kept() [][]float64 {
+	pts := [][]float64{}
+	for i, pos := range c.pts {
+		if c.keep[i] {
+			pts = append(pts, pos)
+		}
+	}
+	if !c.keep[0] {
+		pts = append(pts, pts[0])
+	}
+	return pts
+}
+
+// droppedRuns returns the runs of positions replaced by a simplified edge,
+// from one kept position to the next.
+func (c *chain) droppedRuns() [][][]float64 {
+	idxs := c.keptIndexes()
+	runs := [][][]float64{}
+	for k := 0; k+1 < len(idxs); k++ {
+		if idxs[k+1] > idxs[k]+1 {
+			runs = append(runs, c.pts[idxs[k]:idxs[k+1]+1])
+		}
+	}
+	if !c.keep[0] {
+		// around the dropped start
+		run := append([][]float64{}, c.pts[idxs[len(idxs)-1]:]...)
+		runs = append(runs, append(run, c.pts[1:idxs[0]+1]...))
+	}
+	return runs
+}
+
+func (c *chain) keptIndexes() []int {
+	idxs := []int{}
+	for i, keep := range c.keep {
+		if keep {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
+
+// simplify marks the positions of the chain to keep at its tolerance. Both
+// ends are kept, unless the chain is free, and closed chains keep at least
+// three positions besides their closing one.
+func (c *chain) simplify(method SimplifyMethod) {
+	n := len(c.pts)
+	c.keep = make([]bool, n)
+	c.keep[0], c.keep[n-1] = true, true
+	if c.halvings >= simplifyMaxHalvings {
+		for i := range c.keep {
+			c.keep[i] = true
+		}
+		return
+	}
+
+	minKept := 2
+	if c.isClosed() {
+		minKept = 4
+	}
+	switch method {
+	case DouglasPeucker:
+		c.douglasPeucker(0, n-1)
+	case Visvalingam:
+		c.visvalingam(minKept)
+	}
+
+	// a closed chain collapsed to a line gets back its positions farthest
+	// from what's kept
+	for count := len(c.keptIndexes()); count < minKept && count < n; count++ {
+		best, bestDist := -1, -1.0
+		idxs := c.keptIndexes()
+		for k := 0; k+1 < len(idxs); k++ {
+			for i := idxs[k] + 1; i < idxs[k+1]; i++ {
+				if d := segmentDistance(c.pts[i], c.pts[idxs[k]], c.pts[idxs[k+1]]); d > bestDist {
+					best, bestDist = i, d
+				}
+			}
+		}
+		if best == -1 {
+			break
+		}
+		c.keep[best] = true
+	}
+
+	// a free chain may start anywhere, so its start goes too if it would
+	// have as another position
+	if idxs := c.keptIndexes(); c.free && len(idxs) >= 5 {
+		prev, start, next := c.pts[idxs[len(idxs)-2]], c.pts[0], c.pts[idxs[1]]
+		drop := false
+		switch method {
+		case DouglasPeucker:
+			drop = segmentDistance(start, prev, next) <= c.tolerance
+		case Visvalingam:
+			drop = math.Abs((start[0]-prev[0])*(next[1]-prev[1])-(start[1]-prev[1])*(next[0]-prev[0]))/2 < c.tolerance
+		}
+		if drop {
+			c.keep[0], c.keep[n-1] = false, false
+		}
+	}
+}
+
+func (c *chain) douglasPeucker(first, last int) {
+	stack := [][2]int{{first, last}}
+	for len(stack) > 0 {
+		span := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		best, bestDist := -1, -1.0
+		for i := span[0] + 1; i < span[1]; i++ {
+			if d := segmentDistance(c.pts[i], c.pts[span[0]], c.pts[span[1]]); d > bestDist {
+				best, bestDist = i, d
+			}
+		}
+		// a closed chain always keeps its farthest position
+		closedSpan := toXY(c.pts[span[0]]) == toXY(c.pts[span[1]])
+		if best == -1 || (bestDist <= c.tolerance && !closedSpan) {
+			continue
+		}
+		c.keep[best] = true
+		stack = append(stack, [2]int{span[0], best}, [2]int{best, span[1]})
+	}
+}
+
+// areaItem is a position of a chain by the area of its triangle with its
+// current neighbours.
+type areaItem struct {
+	index int
+	area  float64
+}
+
+type areaHeap []areaItem
+
+func (h areaHeap) Len() int            { return len(h) }
+func (h areaHeap) Less(i, j int) bool  { return h[i].area < h[j].area }
+func (h areaHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *areaHeap) Push(x interface{}) { *h = append(*h, x.(areaItem)) }
+func (h *areaHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+func (c *chain) visvalingam(minKept int) {
+	n := len(c.pts)
+	prev := make([]int, n)
+	next := make([]int, n)
+	areas := make([]float64, n)
+	for i := range c.pts {
+		prev[i], next[i] = i-1, i+1
+		c.keep[i] = true
+	}
+	triangle := func(i int) float64 {
+		a, b, d := c.pts[prev[i]], c.pts[i], c.pts[next[i]]
+		return math.Abs((b[0]-a[0])*(d[1]-a[1])-(b[1]-a[1])*(d[0]-a[0])) / 2
+	}
+
+	h := &areaHeap{}
+	for i := 1; i < n-1; i++ {
+		areas[i] = triangle(i)
+		heap.Push(h, areaItem{index: i, area: areas[i]})
+	}
+	count := n
+	for h.Len() > 0 && count > minKept {
+		item := heap.Pop(h).(areaItem)
+		i := item.index
+		// skip entries made stale by the removal of a neighbour
+		if !c.keep[i] || item.area != areas[i] {
+			continue
+		}
+		if item.area >= c.tolerance {
+			break
+		}
+		c.keep[i] = false
+		count--
+		a, b := prev[i], next[i]
+		next[a], prev[b] = b, a
+		for _, j := range []int{a, b} {
+			if j == 0 || j == n-1 {
+				continue
+			}
+			areas[j] = triangle(j)
+			heap.Push(h, areaItem{index: j, area: areas[j]})
+		}
+	}
+}
+
+// segmentDistance returns the distance from a position to the segment
+// between a and b.
+func segmentDistance(pos, a, b []float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, ((pos[0]-a[0])*dx+(pos[1]-a[1])*dy)/l))
+	}
+	return math.Hypot(pos[0]-(a[0]+t*dx), pos[1]-(a[1]+t*dy))
+}
+
+// findConflicts sweeps over the simplified chains and returns those that
+// meet other than end to end, or that cut off kept positions of other
+// chains.
+func findConflicts(chains []*chain) (map[int]bool, error) {
+	type chainEdge struct {
+		chain, index int
+	}
+	type vertex struct {
+		chain, index int
+	}
+
+	op := newOperation("validate")
+	mpi := &multiPolyIn{isSubject: true}
+	pi := &polyIn{multiPoly: mpi}
+	pi.exteriorRing = &ringIn{poly: pi, isExterior: true}
+	mpi.polys = []*polyIn{pi}
+
+	edges := make(map[*ringIn]chainEdge)
+	points := make([][]*point, len(chains))
+	for i, c := range chains {
+		for _, pos := range c.kept() {
+			points[i] = append(points[i], op.rounder.roundFloat(pos[0], pos[1]))
+		}
+		for k := 0; k+1 < len(points[i]); k++ {
+			edge := &ringIn{poly: pi, isExterior: true}
+			seg, err := op.newSegmentFromRing(points[i][k], points[i][k+1], edge)
+			if err != nil {
+				continue
+			}
+			pi.exteriorRing.segments = append(pi.exteriorRing.segments, seg)
+			edges[edge] = chainEdge{chain: i, index: k}
+		}
+	}
+
+	sl, err := op.sweep([]*multiPolyIn{mpi})
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make(map[int]bool)
+	type node struct {
+		chains   map[int]bool
+		vertices map[vertex]bool
+		interior bool
+	}
+	nodes := make(map[string]*node)
+	for _, seg := range sl.segments {
+		if seg.consumedBy != nil {
+			continue
+		}
+		// overlapping edges were consumed into a single segment
+		if len(seg.rings) > 1 {
+			for _, ring := range seg.rings {
+				conflicts[edges[ring].chain] = true
+			}
+		}
+		for _, ring := range seg.rings {
+			e := edges[ring]
+			for _, pt := range []*point{seg.leftSE.point, seg.rightSE.point} {
+				key := pt.String()
+				nd, ok := nodes[key]
+				if !ok {
+					nd = &node{chains: make(map[int]bool), vertices: make(map[vertex]bool)}
+					nodes[key] = nd
+				}
+				nd.chains[e.chain] = true
+				switch {
+				case pt.equal(*points[e.chain][e.index]):
+					nd.vertices[vertex{e.chain, e.index}] = true
+				case pt.equal(*points[e.chain][e.index+1]):
+					nd.vertices[vertex{e.chain, e.index + 1}] = true
+				default:
+					nd.interior = true
+				}
+			}
+		}
+	}
+	for _, nd := range nodes {
+		ok := !nd.interior
+		if ok && len(nd.vertices) > 1 {
+			// chains may only meet at their ends
+			for v := range nd.vertices {
+				if v.index != 0 && v.index != len(points[v.chain])-1 {
+					ok = false
+				}
+			}
+		}
+		if !ok {
+			for i := range nd.chains {
+				conflicts[i] = true
+			}
+		}
+	}
+
+	for i := range findCutOffs(chains) {
+		conflicts[i] = true
+	}
+	return conflicts, nil
+}
+
+// findCutOffs returns the chains whose simplified edges leave kept positions
+// of other chains between them and the positions they dropped, which would
+// move those positions to the other side of the chain without crossing it.
+func findCutOffs(chains []*chain) map[int]bool {
+	type keptPos struct {
+		chain int
+		pos   []float64
+	}
+	all := []keptPos{}
+	for i, c := range chains {
+		for _, pos := range c.kept() {
+			all = append(all, keptPos{chain: i, pos: pos})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].pos[0] < all[j].pos[0] })
+
+	cutOffs := make(map[int]bool)
+	for i, c := range chains {
+		for _, region := range c.droppedRuns() {
+			// the dropped positions closed by the simplified edge
+			bounds, _ := geomBounds(Geom{{region}})
+			start := sort.Search(len(all), func(j int) bool { return all[j].pos[0] >= bounds[0] })
+			for j := start; j < len(all) && all[j].pos[0] <= bounds[2]; j++ {
+				kp := all[j]
+				if kp.chain == i || kp.pos[1] < bounds[1] || kp.pos[1] > bounds[3] {
+					continue
+				}
+				if toXY(kp.pos) == toXY(region[0]) || toXY(kp.pos) == toXY(region[len(region)-1]) {
+					continue
+				}
+				if insideRing(kp.pos, region) {
+					cutOffs[i] = true
+					break
+				}
+			}
+			if cutOffs[i] {
+				break
+			}
+		}
+	}
+	return cutOffs
+}
+
+// insideRing tells whether a position is inside a ring, closed or not, by
+// the even-odd rule.
+func insideRing(pos []float64, ring [][]float64) bool {
+	inside := false
+	n := len(ring)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > pos[1]) != (b[1] > pos[1]) &&
+			pos[0] < (b[0]-a[0])*(pos[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,137 @@
+package polygol
+
+import (
+	"math"
+	"testing"
+)
+
+// wiggle returns the positions from a to b, excluding b, with the ones in
+// between pushed sideways by up to 0.01.
+func wiggle(a, b []float64, n int) [][]float64 {
+	pts := [][]float64{}
+	for i := 0; i < n; i++ {
+		t := float64(i) / float64(n)
+		off := 0.0
+		if i > 0 {
+			off = 0.01 * math.Sin(float64(i))
+		}
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		l := math.Hypot(dx, dy)
+		pts = append(pts, []float64{a[0] + t*dx - off*dy/l, a[1] + t*dy + off*dx/l})
+	}
+	return pts
+}
+
+func wigglyRing(corners [][]float64, n int) [][]float64 {
+	ring := [][]float64{}
+	for i := 0; i+1 < len(corners); i++ {
+		ring = append(ring, wiggle(corners[i], corners[i+1], n)...)
+	}
+	return append(ring, ring[0])
+}
+
+func TestSimplify(t *testing.T) {
+	square := Geom{{wigglyRing([][]float64{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}, 20)}}
+	for _, c := range []struct {
+		method    SimplifyMethod
+		tolerance float64
+	}{{DouglasPeucker, 0.1}, {Visvalingam, 0.5}} {
+		result, err := Simplify(square, c.tolerance, c.method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result) != 1 || len(result[0]) != 1 || len(result[0][0]) != 5 {
+			t.Errorf("method %d: expected the 4 corners, got %v", c.method, result)
+			continue
+		}
+		if area := result.Area(); math.Abs(area-100) > 1e-9 {
+			t.Errorf("method %d: expected area 100, got %v", c.method, area)
+		}
+	}
+
+	// a small triangle can't collapse
+	triangle := Geom{{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}}}
+	result, err := Simplify(triangle, 10, DouglasPeucker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result[0][0]) != 4 {
+		t.Errorf("expected the triangle back, got %v", result)
+	}
+
+	if _, err := Simplify(square, -1, DouglasPeucker); err == nil {
+		t.Errorf("expected an error for a negative tolerance")
+	}
+}
+
+func TestSimplifyKeepsTopology(t *testing.T) {
+	// a bump on top of a square, too small to keep by itself
+	shell := [][]float64{{0, 0}, {10, 0}, {10, 10}, {5.5, 10}, {5, 10.4}, {4.5, 10}, {0, 10}, {0, 0}}
+	for name, hole := range map[string][][]float64{
+		"hole in the bump":            {{4.9, 10.1}, {5, 10.3}, {5.1, 10.1}, {4.9, 10.1}},
+		"hole across the bump's base": {{4.9, 9.8}, {5, 10.3}, {5.1, 9.8}, {4.9, 9.8}},
+	} {
+		result, err := Simplify(Geom{{shell, hole}}, 1, DouglasPeucker)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result[0][0]) != len(shell) {
+			t.Errorf("%s: expected the bump kept, got %v", name, result)
+		}
+		if issues := mustValidate(t, result); len(issues) > 0 {
+			t.Errorf("%s: expected a valid result, got %v", name, issues)
+		}
+	}
+
+	// without the hole the bump goes
+	result, err := Simplify(Geom{{shell}}, 1, DouglasPeucker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result[0][0]) != 5 {
+		t.Errorf("expected a square, got %v", result)
+	}
+}
+
+func TestSimplifyCoverage(t *testing.T) {
+	// two squares sharing a wiggly edge, and an island within the right one
+	shared := wiggle([]float64{10, 0}, []float64{10, 10}, 20)
+	left := [][]float64{{0, 0}}
+	left = append(left, shared...)
+	left = append(left, []float64{10, 10}, []float64{0, 10}, []float64{0, 0})
+	right := [][]float64{{10, 10}}
+	right = append(right, reversePositions(shared)...)
+	right = append(right, []float64{20, 0}, []float64{20, 10}, []float64{10, 10})
+	// the island is a hole of the right square and a polygon of its own
+	island := wigglyRing([][]float64{{14, 4}, {14, 6}, {16, 6}, {16, 4}, {14, 4}}, 10)
+
+	coverage := []Geom{{{left}}, {{right, island}}, {{reversePositions(island)}}}
+	result, err := SimplifyCoverage(coverage, 0.1, DouglasPeucker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, geom := range result {
+		if issues := mustValidate(t, geom); len(issues) > 0 {
+			t.Errorf("geometry %d: expected a valid result, got %v", i, issues)
+		}
+	}
+	if n := len(result[0][0][0]); n != 5 {
+		t.Errorf("expected the shared edge simplified, got %d positions", n)
+	}
+
+	// the neighbours still neither overlap nor leave gaps
+	union, err := Union(result[0], result[1], result[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if area := union.Area(); math.Abs(area-200) > 1e-9 {
+		t.Errorf("expected the coverage to keep area 200, got %v", area)
+	}
+	total := 0.0
+	for _, geom := range result {
+		total += geom.Area()
+	}
+	if math.Abs(total-200) > 1e-9 {
+		t.Errorf("expected the areas to add up to 200, got %v", total)
+	}
+}
@@ -0,0 +1,648 @@
+package polygol
+
+import (
+	"math"
+	"sort"
+)
+
+// A Polygol with great-circle edges reads coordinates as longitude and
+// latitude in degrees, the edge between two positions being the shorter arc
+// of the great circle through them, and every ring enclosing the smaller of
+// the two areas it divides the sphere into.
+//
+// Rather than a second kernel for the sweep, the operations run in gnomonic
+// projections, which map great circles to straight lines: the planar sweep
+// on the projected inputs is the sweep on the sphere, and intersections of
+// projected edges project back to intersections of the arcs.
+//
+// When all inputs lie within maxGnomonicAngle of their mean position, they
+// are projected around it and the results come out whole. Otherwise the
+// sphere is split along the faces of a cube, as S2 does: every ring is
+// clipped to each face on the sphere and projected onto that face, the
+// operation runs face by face and the pieces of the results are merged back
+// together across the edges of the faces.
+//
+// Positions of the output that were positions of the input keep their exact
+// coordinates. The others are projected back, with longitudes in
+// [-180, 180], and edges crossing the antimeridian simply do.
+
+// Edges tells how the edge between two consecutive positions of a ring runs.
+type Edges int
+
+const (
+	// Planar edges are straight lines in the plane of the coordinates.
+	Planar Edges = iota
+	// GreatCircle edges are the shorter arcs of great circles between
+	// longitude and latitude positions in degrees.
+	GreatCircle
+)
+
+// maxGnomonicAngle is how far from the center of a single projection the
+// inputs may lie, in degrees. Gnomonic coordinates grow without bound
+// towards 90°, and with them the rounding of the planar sweep.
+const maxGnomonicAngle = 80
+
+// Spherical returns a Polygol whose edges are great-circle arcs.
+func Spherical() *Polygol {
+	return &Polygol{Edges: GreatCircle}
+}
+
+type vec3 [3]float64
+
+func (a vec3) dot(b vec3) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func (a vec3) cross(b vec3) vec3 {
+	return vec3{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func (a vec3) add(b vec3) vec3 {
+	return vec3{a[0] + b[0], a[1] + b[1], a[2] + b[2]}
+}
+
+func (a vec3) scale(s float64) vec3 {
+	return vec3{a[0] * s, a[1] * s, a[2] * s}
+}
+
+func (a vec3) normalize() vec3 {
+	return a.scale(1 / math.Sqrt(a.dot(a)))
+}
+
+func lonLatToVec3(pos []float64) vec3 {
+	lon, lat := pos[0]*math.Pi/180, pos[1]*math.Pi/180
+	return vec3{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)}
+}
+
+func vec3ToLonLat(v vec3) []float64 {
+	return []float64{
+		math.Atan2(v[1], v[0]) * 180 / math.Pi,
+		math.Atan2(v[2], math.Hypot(v[0], v[1])) * 180 / math.Pi,
+	}
+}
+
+// gnomonic projects the sphere onto the plane tangent at center, with axes
+// u and v.
+type gnomonic struct {
+	center, u, v vec3
+	// input positions by their projection, to restore them exactly
+	original map[[2]float64][]float64
+}
+
+func newGnomonic(center vec3) *gnomonic {
+	axis := vec3{0, 0, 1}
+	if math.Abs(center[2]) > 0.9 {
+		axis = vec3{1, 0, 0}
+	}
+	u := axis.cross(center).normalize()
+	return &gnomonic{
+		center:   center,
+		u:        u,
+		v:        center.cross(u),
+		original: map[[2]float64][]float64{},
+	}
+}
+
+// project returns the plane coordinates of a position in the hemisphere
+// around the center.
+func (g *gnomonic) project(p vec3) []float64 {
+	d := p.dot(g.center)
+	return []float64{p.dot(g.u) / d, p.dot(g.v) / d}
+}
+
+func (g *gnomonic) unproject(pt []float64) []float64 {
+	if pos, ok := g.original[[2]float64{pt[0], pt[1]}]; ok {
+		return pos
+	}
+	return vec3ToLonLat(g.center.add(g.u.scale(pt[0])).add(g.v.scale(pt[1])).normalize())
+}
+
+// projectRing projects a ring given as unit vectors, remembering where its
+// input positions came from.
+func (g *gnomonic) projectRing(ring []vec3, positions [][]float64) [][]float64 {
+	out := make([][]float64, len(ring))
+	for i, p := range ring {
+		out[i] = g.project(p)
+		if positions != nil {
+			g.original[[2]float64{out[i][0], out[i][1]}] = positions[i]
+		}
+	}
+	return out
+}
+
+func (g *gnomonic) unprojectGeom(geom Geom) Geom {
+	out := make(Geom, len(geom))
+	for i, poly := range geom {
+		out[i] = make([][][]float64, len(poly))
+		for j, ring := range poly {
+			out[i][j] = make([][]float64, len(ring))
+			for k, pt := range ring {
+				out[i][j][k] = g.unproject(pt)
+			}
+		}
+	}
+	return out
+}
+
+// sphericalRing is a ring as unit vectors without its closing position,
+// along with the input positions they come from.
+type sphericalRing struct {
+	points    []vec3
+	positions [][]float64
+}
+
+func toSphericalGeoms(geoms []Geom) [][][]sphericalRing {
+	out := make([][][]sphericalRing, len(geoms))
+	for i, geom := range geoms {
+		for _, poly := range geom {
+			rings := []sphericalRing{}
+			for _, ring := range poly {
+				r := sphericalRing{}
+				for _, pos := range ring {
+					if len(pos) < 2 {
+						continue
+					}
+					r.points = append(r.points, lonLatToVec3(pos))
+					r.positions = append(r.positions, pos)
+				}
+				if n := len(r.points); n > 1 && r.points[0] == r.points[n-1] {
+					r.points, r.positions = r.points[:n-1], r.positions[:n-1]
+				}
+				rings = append(rings, r)
+			}
+			out[i] = append(out[i], rings)
+		}
+	}
+	return out
+}
+
+// runSpherical runs a Boolean operation with great-circle edges.
+func (p *Polygol) runSpherical(opType string, geom Geom, moreGeoms []Geom) (Geom, error) {
+	geoms := toSphericalGeoms(append([]Geom{geom}, moreGeoms...))
+
+	if center, ok := sphericalCenter(geoms); ok {
+		g := newGnomonic(center)
+		projected := make([]Geom, len(geoms))
+		for i, polys := range geoms {
+			projected[i] = Geom{}
+			for _, rings := range polys {
+				poly := [][][]float64{}
+				for _, r := range rings {
+					poly = append(poly, closeRing(g.projectRing(r.points, r.positions)))
+				}
+				projected[i] = append(projected[i], poly)
+			}
+		}
+		result, err := p.newOperation(opType).run(projected[0], projected[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		return g.unprojectGeom(result), nil
+	}
+
+	pieces := []sphericalPiece{}
+	for _, face := range cubeFaces() {
+		projected := make([]Geom, len(geoms))
+		empty := true
+		for i, polys := range geoms {
+			projected[i] = face.clipGeom(polys)
+			empty = empty && len(projected[i]) == 0
+		}
+		if empty {
+			continue
+		}
+		faceResult, err := p.newOperation(opType).run(projected[0], projected[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		pieces = append(pieces, face.pieces(faceResult)...)
+	}
+	return mergePieces(pieces), nil
+}
+
+// sphericalCenter returns the mean position of the inputs, if they all lie
+// close enough to it for a single projection.
+func sphericalCenter(geoms [][][]sphericalRing) (vec3, bool) {
+	sum := vec3{}
+	for _, polys := range geoms {
+		for _, rings := range polys {
+			for _, r := range rings {
+				for _, pt := range r.points {
+					sum = sum.add(pt)
+				}
+			}
+		}
+	}
+	if sum.dot(sum) < 1e-12 {
+		return sum, false
+	}
+	center := sum.normalize()
+	minDot := math.Cos(maxGnomonicAngle * math.Pi / 180)
+	for _, polys := range geoms {
+		for _, rings := range polys {
+			for _, r := range rings {
+				for _, pt := range r.points {
+					if pt.dot(center) < minDot {
+						return center, false
+					}
+				}
+			}
+		}
+	}
+	return center, true
+}
+
+func closeRing(ring [][]float64) [][]float64 {
+	if len(ring) == 0 {
+		return ring
+	}
+	first, last := ring[0], ring[len(ring)-1]
+	if first[0] != last[0] || first[1] != last[1] {
+		ring = append(ring, []float64{first[0], first[1]})
+	}
+	return ring
+}
+
+// cubeFace is the gnomonic projection onto one face of a cube around the
+// sphere, along with the planes bounding the face on the sphere.
+type cubeFace struct {
+	*gnomonic
+	planes [4]vec3
+}
+
+func cubeFaces() []cubeFace {
+	axes := []vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {-1, 0, 0}, {0, -1, 0}, {0, 0, -1}}
+	faces := make([]cubeFace, len(axes))
+	for i, axis := range axes {
+		g := newGnomonic(axis)
+		faces[i] = cubeFace{
+			gnomonic: g,
+			// the face is where |u| and |v| are at most 1
+			planes: [4]vec3{
+				axis.add(g.u.scale(-1)), axis.add(g.u),
+				axis.add(g.v.scale(-1)), axis.add(g.v),
+			},
+		}
+	}
+	return faces
+}
+
+// clipGeom clips the rings of a geometry to the face, dropping polygons
+// whose exterior ring falls outside of it, and projects them onto it.
+func (f cubeFace) clipGeom(polys [][]sphericalRing) Geom {
+	geom := Geom{}
+	for _, rings := range polys {
+		poly := [][][]float64{}
+		for j, r := range rings {
+			clipped := f.clipRing(r)
+			if len(clipped) < 3 {
+				if j == 0 {
+					break
+				}
+				continue
+			}
+			poly = append(poly, closeRing(clipped))
+		}
+		if len(poly) > 0 {
+			geom = append(geom, poly)
+		}
+	}
+	return geom
+}
+
+// clipRing clips a ring to the face with the Sutherland-Hodgman algorithm on
+// the sphere: each bounding plane of the face goes through the center of the
+// sphere, so the point where an edge crosses it is on the chord between the
+// ends of the edge, projected onto the sphere.
+func (f cubeFace) clipRing(r sphericalRing) [][]float64 {
+	points, positions := r.points, r.positions
+	for _, n := range f.planes {
+		if len(points) == 0 {
+			break
+		}
+		clippedPoints := []vec3{}
+		clippedPositions := [][]float64{}
+		for i, cur := range points {
+			j := (i + len(points) - 1) % len(points)
+			prev := points[j]
+			dCur, dPrev := n.dot(cur), n.dot(prev)
+			if (dCur >= 0) != (dPrev >= 0) {
+				t := dPrev / (dPrev - dCur)
+				crossing := prev.add(cur.add(prev.scale(-1)).scale(t)).normalize()
+				clippedPoints = append(clippedPoints, crossing)
+				clippedPositions = append(clippedPositions, nil)
+			}
+			if dCur >= 0 {
+				clippedPoints = append(clippedPoints, cur)
+				clippedPositions = append(clippedPositions, positions[i])
+			}
+		}
+		points, positions = clippedPoints, clippedPositions
+	}
+
+	ring := make([][]float64, len(points))
+	for i, p := range points {
+		ring[i] = f.project(p)
+		// crossings are on the edges of the face, whatever the rounding
+		for k := range ring[i] {
+			if math.Abs(math.Abs(ring[i][k])-1) < 1e-12 {
+				ring[i][k] = math.Copysign(1, ring[i][k])
+			}
+		}
+		if positions[i] != nil {
+			f.original[[2]float64{ring[i][0], ring[i][1]}] = positions[i]
+		}
+	}
+	return ring
+}
+
+// Merging the results of the faces
+// ================================
+//
+// The pieces of the results on either side of an edge of a face meet along
+// it, with an edge running one way on one side and the other way on the
+// other: the points on the edges of the faces are snapped together, the
+// edges in the way are split at them, and the pairs of opposite edges are
+// dropped. What's left is walked into the rings of the merged polygons.
+
+// seamTolerance is how close to an edge of a face, in face coordinates, a
+// point of a result is on it, and how close, on the unit sphere, points on
+// the edges are the same point.
+const seamTolerance = 1e-9
+
+type sphericalVertex struct {
+	v   vec3
+	pos []float64
+	// on an edge of a face
+	seam bool
+}
+
+func (sv *sphericalVertex) key() [2]float64 {
+	return [2]float64{sv.pos[0], sv.pos[1]}
+}
+
+// sphericalPiece is a polygon of the result on one face, its rings without
+// their closing positions.
+type sphericalPiece [][]*sphericalVertex
+
+func (f cubeFace) pieces(geom Geom) []sphericalPiece {
+	out := make([]sphericalPiece, len(geom))
+	for i, poly := range geom {
+		for _, ring := range poly {
+			vertices := []*sphericalVertex{}
+			for k, pt := range ring {
+				if k == len(ring)-1 && k > 0 && pt[0] == ring[0][0] && pt[1] == ring[0][1] {
+					break
+				}
+				vertices = append(vertices, &sphericalVertex{
+					v:    f.center.add(f.u.scale(pt[0])).add(f.v.scale(pt[1])).normalize(),
+					pos:  f.unproject(pt),
+					seam: math.Abs(math.Abs(pt[0])-1) < seamTolerance || math.Abs(math.Abs(pt[1])-1) < seamTolerance,
+				})
+			}
+			out[i] = append(out[i], vertices)
+		}
+	}
+	return out
+}
+
+type sphericalEdge struct {
+	from, to *sphericalVertex
+	piece    int
+	// dropped against an opposite edge, or walked into a ring
+	dropped, walked bool
+}
+
+func mergePieces(pieces []sphericalPiece) Geom {
+	seam := snapSeams(pieces)
+
+	edges := []*sphericalEdge{}
+	for i, piece := range pieces {
+		for _, ring := range piece {
+			for k, from := range ring {
+				to := ring[(k+1)%len(ring)]
+				if from.key() == to.key() {
+					continue
+				}
+				path := []*sphericalVertex{from}
+				if from.seam && to.seam {
+					path = append(path, seamBetween(from, to, seam)...)
+				}
+				path = append(path, to)
+				for l := 1; l < len(path); l++ {
+					edges = append(edges, &sphericalEdge{from: path[l-1], to: path[l], piece: i})
+				}
+			}
+		}
+	}
+
+	// pieces meeting along an edge of a face are parts of the same polygon
+	group := make([]int, len(pieces))
+	for i := range group {
+		group[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if group[i] != i {
+			group[i] = find(group[i])
+		}
+		return group[i]
+	}
+	merged := make([]bool, len(pieces))
+	byEnds := map[[2][2]float64][]*sphericalEdge{}
+	for _, e := range edges {
+		reverse := [2][2]float64{e.to.key(), e.from.key()}
+		if opposite := byEnds[reverse]; len(opposite) > 0 {
+			other := opposite[len(opposite)-1]
+			byEnds[reverse] = opposite[:len(opposite)-1]
+			e.dropped, other.dropped = true, true
+			merged[e.piece], merged[other.piece] = true, true
+			group[find(e.piece)] = find(other.piece)
+			continue
+		}
+		ends := [2][2]float64{e.from.key(), e.to.key()}
+		byEnds[ends] = append(byEnds[ends], e)
+	}
+
+	outgoing := map[[2]float64][]*sphericalEdge{}
+	for _, e := range edges {
+		if !e.dropped && merged[e.piece] {
+			outgoing[e.from.key()] = append(outgoing[e.from.key()], e)
+		}
+	}
+	rings := map[int][][]*sphericalVertex{}
+	for _, e := range edges {
+		if e.dropped || e.walked || !merged[e.piece] {
+			continue
+		}
+		if ring := walkRing(e, outgoing); len(ring) > 2 {
+			g := find(e.piece)
+			rings[g] = append(rings[g], ring)
+		}
+	}
+
+	result := Geom{}
+	done := map[int]bool{}
+	for i, piece := range pieces {
+		if !merged[i] {
+			result = append(result, piece.geom())
+			continue
+		}
+		g := find(i)
+		if done[g] {
+			continue
+		}
+		done[g] = true
+		// every ring has the polygon on its left, and the exterior is the
+		// one enclosing the least
+		groupRings := rings[g]
+		sort.SliceStable(groupRings, func(a, b int) bool {
+			return leftArea(groupRings[a]) < leftArea(groupRings[b])
+		})
+		if len(groupRings) > 0 {
+			result = append(result, sphericalPiece(groupRings).geom())
+		}
+	}
+	return result
+}
+
+// snapSeams gives the points on the edges of the faces that are the same
+// point the same coordinates, returning one of each.
+func snapSeams(pieces []sphericalPiece) []*sphericalVertex {
+	vertices := []*sphericalVertex{}
+	for _, piece := range pieces {
+		for _, ring := range piece {
+			for _, sv := range ring {
+				if sv.seam {
+					vertices = append(vertices, sv)
+				}
+			}
+		}
+	}
+	sort.SliceStable(vertices, func(i, j int) bool { return vertices[i].v[0] < vertices[j].v[0] })
+
+	snapped := make([]bool, len(vertices))
+	seam := []*sphericalVertex{}
+	for i, sv := range vertices {
+		if snapped[i] {
+			continue
+		}
+		seam = append(seam, sv)
+		for j := i + 1; j < len(vertices) && vertices[j].v[0]-sv.v[0] < seamTolerance; j++ {
+			d := vertices[j].v.add(sv.v.scale(-1))
+			if !snapped[j] && d.dot(d) < seamTolerance*seamTolerance {
+				snapped[j] = true
+				vertices[j].v, vertices[j].pos = sv.v, sv.pos
+			}
+		}
+	}
+	return seam
+}
+
+// seamBetween returns the points on the edges of the faces that lie on the
+// arc from a to b, in order.
+func seamBetween(a, b *sphericalVertex, seam []*sphericalVertex) []*sphericalVertex {
+	n := a.v.cross(b.v)
+	if n.dot(n) == 0 {
+		return nil
+	}
+	n = n.normalize()
+	between := []*sphericalVertex{}
+	for _, sv := range seam {
+		if sv.key() == a.key() || sv.key() == b.key() {
+			continue
+		}
+		if math.Abs(n.dot(sv.v)) < seamTolerance && a.v.cross(sv.v).dot(n) > 0 && sv.v.cross(b.v).dot(n) > 0 {
+			between = append(between, sv)
+		}
+	}
+	sort.SliceStable(between, func(i, j int) bool {
+		return a.v.dot(between[i].v) > a.v.dot(between[j].v)
+	})
+	return between
+}
+
+// walkRing follows the edges left from e until it gets back to e. Where a
+// ring passes through a point more than once, it turns onto the edge that
+// keeps the polygon on its left, the first one clockwise from where it came
+// from.
+func walkRing(e *sphericalEdge, outgoing map[[2]float64][]*sphericalEdge) []*sphericalVertex {
+	ring := []*sphericalVertex{}
+	cur := e
+	for {
+		cur.walked = true
+		ring = append(ring, cur.from)
+		var next *sphericalEdge
+		best := 0.0
+		for _, candidate := range outgoing[cur.to.key()] {
+			angle := clockwiseAngle(cur.to.v, cur.from.v, candidate.to.v)
+			if next == nil || angle < best {
+				next, best = candidate, angle
+			}
+		}
+		if next == nil || next == e {
+			break
+		}
+		if next.walked {
+			return nil
+		}
+		cur = next
+	}
+	return dropSeamVertices(ring)
+}
+
+// clockwiseAngle returns the angle at v, in (0, 2π], from the direction of a
+// clockwise to the direction of b.
+func clockwiseAngle(v, a, b vec3) float64 {
+	ta := a.add(v.scale(-v.dot(a)))
+	tb := b.add(v.scale(-v.dot(b)))
+	angle := -math.Atan2(v.dot(ta.cross(tb)), ta.dot(tb))
+	if angle <= 0 {
+		angle += 2 * math.Pi
+	}
+	return angle
+}
+
+// dropSeamVertices drops the points on the edges of the faces that a merged
+// ring passes straight through.
+func dropSeamVertices(ring []*sphericalVertex) []*sphericalVertex {
+	out := []*sphericalVertex{}
+	for k, sv := range ring {
+		if sv.seam {
+			prev, next := ring[(k+len(ring)-1)%len(ring)], ring[(k+1)%len(ring)]
+			n := prev.v.cross(next.v)
+			if n.dot(n) > 0 && math.Abs(n.normalize().dot(sv.v)) < seamTolerance &&
+				prev.v.cross(sv.v).dot(n) > 0 && sv.v.cross(next.v).dot(n) > 0 {
+				continue
+			}
+		}
+		out = append(out, sv)
+	}
+	return out
+}
+
+// leftArea returns the area of the unit sphere to the left of a ring, from
+// the turns of its edges.
+func leftArea(ring []*sphericalVertex) float64 {
+	turns := 0.0
+	for k, sv := range ring {
+		prev, next := ring[(k+len(ring)-1)%len(ring)], ring[(k+1)%len(ring)]
+		n1, n2 := prev.v.cross(sv.v), sv.v.cross(next.v)
+		turns += math.Atan2(sv.v.dot(n1.cross(n2)), n1.dot(n2))
+	}
+	return 2*math.Pi - turns
+}
+
+func (piece sphericalPiece) geom() [][][]float64 {
+	poly := make([][][]float64, len(piece))
+	for j, ring := range piece {
+		poly[j] = make([][]float64, 0, len(ring)+1)
+		for _, sv := range ring {
+			poly[j] = append(poly[j], sv.pos)
+		}
+		if len(ring) > 0 {
+			poly[j] = append(poly[j], []float64{ring[0].pos[0], ring[0].pos[1]})
+		}
+	}
+	return poly
+}
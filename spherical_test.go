@@ -0,0 +1,175 @@
+package polygol
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSphericalArcs(t *testing.T) {
+	// the great circle from (0, 45) to (90, 45) bulges north to 54.7° at
+	// longitude 45, clearing the top of the strip
+	triangle := Geom{{{{0, 45}, {90, 45}, {45, 80}, {0, 45}}}}
+	strip := Geom{{{{44, 0}, {46, 0}, {46, 50}, {44, 50}, {44, 0}}}}
+
+	planar, err := Intersection(triangle, strip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(planar) != 1 {
+		t.Errorf("expected the planar edge to cut the strip, got %v", planar)
+	}
+
+	spherical, err := Spherical().Intersection(triangle, strip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spherical) != 0 {
+		t.Errorf("expected the arc to clear the strip, got %v", spherical)
+	}
+
+	taller := Geom{{{{44, 0}, {46, 0}, {46, 60}, {44, 60}, {44, 0}}}}
+	spherical, err = Spherical().Intersection(triangle, taller)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spherical) != 1 {
+		t.Fatalf("expected one polygon, got %v", spherical)
+	}
+	minLat := 90.0
+	for _, pt := range spherical[0][0] {
+		minLat = math.Min(minLat, pt[1])
+	}
+	// tan(lat) = √2 cos(lon - 45°) along the arc, at the sides of the strip
+	if expected := math.Atan(math.Sqrt2*math.Cos(math.Pi/180)) * 180 / math.Pi; math.Abs(minLat-expected) > 1e-9 {
+		t.Errorf("expected the arc at latitude %v, got %v", expected, minLat)
+	}
+}
+
+func TestSphericalKeepsPositions(t *testing.T) {
+	// the short way across the antimeridian, with no special handling
+	fiji := Geom{{{{178, -17}, {-179, -17}, {-179, -16}, {178, -16}, {178, -17}}}}
+	result, err := Spherical().Union(fiji)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || len(result[0]) != 1 || len(result[0][0]) != 5 {
+		t.Fatalf("expected a single square, got %v", result)
+	}
+	for _, pt := range result[0][0] {
+		found := false
+		for _, pos := range fiji[0][0] {
+			found = found || pt[0] == pos[0] && pt[1] == pos[1]
+		}
+		if !found {
+			t.Errorf("position %v isn't an input position", pt)
+		}
+	}
+}
+
+func TestSphericalPolarCap(t *testing.T) {
+	// a ring around the south pole encloses it, the smaller side
+	antarctica := Geom{{{{0, -80}, {90, -80}, {180, -80}, {-90, -80}, {0, -80}}}}
+	pole := Geom{{{{0, -89}, {120, -89}, {-120, -89}, {0, -89}}}}
+	within, err := Spherical().Difference(pole, antarctica)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(within) != 0 {
+		t.Errorf("expected the pole to be inside the cap, got %v", within)
+	}
+}
+
+func TestSphericalCubeFaces(t *testing.T) {
+	// far apart inputs take the cube faces, and the square across longitude
+	// 45 is merged back together across the edge between two of them
+	across := Geom{{{{40, -5}, {50, -5}, {50, 5}, {40, 5}, {40, -5}}}}
+	opposite := Geom{{{{-150, -5}, {-145, -5}, {-145, 5}, {-150, 5}, {-150, -5}}}}
+	geoms := toSphericalGeoms([]Geom{across, opposite})
+	if _, ok := sphericalCenter(geoms); ok {
+		t.Fatalf("expected no single projection")
+	}
+
+	result, err := Spherical().Union(across, opposite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected 2 polygons, got %v", result)
+	}
+	for _, poly := range result {
+		for _, pt := range poly[0] {
+			if math.Abs(pt[0]-45) < 1e-9 {
+				t.Errorf("expected no point on the edge of the faces, got %v", result)
+			}
+		}
+	}
+
+	overlap, err := Spherical().Intersection(across, opposite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlap) != 0 {
+		t.Errorf("expected no overlap, got %v", overlap)
+	}
+
+	// a strip across three faces comes out whole
+	strip := Geom{{{{-60, -10}, {60, -10}, {60, 10}, {-60, 10}, {-60, -10}}}}
+	result, err = Spherical().Union(strip, Geom{{{{100, 0}, {120, 0}, {120, 20}, {100, 20}, {100, 0}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || len(result[0][0]) != 5 {
+		t.Errorf("expected the strip and the square, got %v", result)
+	}
+}
+
+func TestSphericalCubeFacesUnion(t *testing.T) {
+	opposite := Geom{{{{-150, -5}, {-145, -5}, {-145, 5}, {-150, 5}, {-150, -5}}}}
+
+	// a union across the edge of two faces, where the boundary of the result
+	// crosses it at points of neither input
+	a := Geom{{{{40, -5}, {50, -5}, {50, 5}, {40, 5}, {40, -5}}}}
+	b := Geom{{{{44, -2}, {48, -2}, {48, 8}, {44, 8}, {44, -2}}}}
+	result, err := Spherical().Union(a, b, opposite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || len(result[0]) != 1 || len(result[0][0]) != 9 {
+		t.Fatalf("expected the union and the opposite square, got %v", result)
+	}
+	seen := map[[2]float64]int{}
+	for _, pt := range result[0][0][:8] {
+		seen[[2]float64{pt[0], pt[1]}]++
+		if math.Abs(pt[0]-45) < 1e-9 {
+			t.Errorf("expected no point on the edge of the faces, got %v", result)
+		}
+	}
+	if len(seen) != 8 {
+		t.Errorf("expected distinct points, got %v", result)
+	}
+
+	// the hole around a corner of the cube comes out of the pieces on three
+	// faces, after the exterior
+	outer := [][]float64{{25, 15}, {65, 15}, {65, 55}, {25, 55}, {25, 15}}
+	inner := Geom{{{{35, 25}, {55, 25}, {55, 45}, {35, 45}, {35, 25}}}}
+	result, err = Spherical().Difference(Geom{{outer}}, inner, opposite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || len(result[0]) != 2 || result[0][0][0][0] != 25 || result[0][1][0][0] != 35 {
+		t.Errorf("expected the frame around the corner, got %v", result)
+	}
+}
+
+func TestSphericalEvenOdd(t *testing.T) {
+	p := Spherical()
+	p.FillRule = EvenOdd
+	square := [][][]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}}
+	result, err := p.MakeValid(Geom{square, square})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 0 {
+		t.Errorf("expected duplicates to cancel out, got %v", result)
+	}
+}
@@ -0,0 +1,496 @@
+package polygol
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ValidationIssueType is the kind of problem reported by Validate.
+type ValidationIssueType int
+
+const (
+	// SelfIntersection is reported where ring edges cross or overlap, either
+	// within one ring or between rings.
+	SelfIntersection ValidationIssueType = iota
+	// RingSelfIntersection is reported where a ring touches itself at a
+	// point without crossing.
+	RingSelfIntersection
+	// HoleOutsideShell is reported for an interior ring that does not lie
+	// within the exterior ring of its polygon.
+	HoleOutsideShell
+	// NestedHoles is reported for an interior ring that lies within another
+	// interior ring of the same polygon.
+	NestedHoles
+	// NestedShells is reported for an exterior ring that lies within the
+	// interior of another polygon.
+	NestedShells
+	// WrongOrientation is reported for an exterior ring that is not
+	// counter-clockwise or an interior ring that is not clockwise.
+	WrongOrientation
+	// TooFewPoints is reported for a ring with fewer than three distinct
+	// points or a polygon without rings.
+	TooFewPoints
+	// RingNotClosed is reported for a ring whose last point is not its first.
+	RingNotClosed
+	// InvalidCoordinates is reported for a ring with a position of fewer
+	// than two coordinates.
+	InvalidCoordinates
+)
+
+func (t ValidationIssueType) String() string {
+	switch t {
+	case SelfIntersection:
+		return "self-intersection"
+	case RingSelfIntersection:
+		return "ring self-intersection"
+	case HoleOutsideShell:
+		return "hole outside shell"
+	case NestedHoles:
+		return "nested holes"
+	case NestedShells:
+		return "nested shells"
+	case WrongOrientation:
+		return "wrong orientation"
+	case TooFewPoints:
+		return "too few points"
+	case RingNotClosed:
+		return "ring not closed"
+	case InvalidCoordinates:
+		return "invalid coordinates"
+	}
+	return fmt.Sprintf("ValidationIssueType(%d)", int(t))
+}
+
+// ValidationIssue is a single problem found by Validate. Polygon and Ring
+// index into the validated geometry and X, Y is where the problem was found.
+type ValidationIssue struct {
+	Type    ValidationIssueType
+	Polygon int
+	Ring    int
+	X, Y    float64
+}
+
+func (vi ValidationIssue) String() string {
+	return fmt.Sprintf("%s at [%f, %f] (polygon %d, ring %d)", vi.Type, vi.X, vi.Y, vi.Polygon, vi.Ring)
+}
+
+// validationRing is a ring that passed the structural checks, with its
+// rounded points deduplicated and without the closing point.
+type validationRing struct {
+	polygon    int
+	ring       int
+	isExterior bool
+	points     []*point
+	in         *ringIn
+	poly       *validationPoly
+}
+
+type validationPoly struct {
+	shell *validationRing
+	holes []*validationRing
+}
+
+// validationEdge is a single edge of a ring, from points[index] to
+// points[index+1]. Every edge gets its own ringIn so that the segments left
+// over after the sweep can be traced back to the input edges.
+type validationEdge struct {
+	ring  *validationRing
+	index int
+}
+
+// passage is one pass of a ring through a point, coming from one neighbour
+// and going to another. A straight passage goes through the interior of an
+// edge rather than through a vertex.
+type passage struct {
+	ring     *validationRing
+	from, to *point
+	straight bool
+}
+
+type validator struct {
+	op       *operation
+	polys    []*validationPoly
+	edges    map[*ringIn]validationEdge
+	issues   []ValidationIssue
+	reported map[string]bool
+}
+
+func newValidator() *validator {
+	return &validator{
+		op:       newOperation("validate"),
+		edges:    make(map[*ringIn]validationEdge),
+		reported: make(map[string]bool),
+	}
+}
+
+func (v *validator) report(t ValidationIssueType, polygon, ring int, x, y float64) {
+	key := fmt.Sprint(t, polygon, ring, x, y)
+	if v.reported[key] {
+		return
+	}
+	v.reported[key] = true
+	v.issues = append(v.issues, ValidationIssue{Type: t, Polygon: polygon, Ring: ring, X: x, Y: y})
+}
+
+func (v *validator) reportAt(t ValidationIssueType, ring *validationRing, pt *point) {
+	v.report(t, ring.polygon, ring.ring, pt.x.number(), pt.y.number())
+}
+
+func (v *validator) validate(geom Geom) ([]ValidationIssue, error) {
+
+	mpi := &multiPolyIn{isSubject: true}
+
+	for i := 0; i < len(geom); i++ {
+		if len(geom[i]) == 0 {
+			v.report(TooFewPoints, i, 0, 0, 0)
+			continue
+		}
+		pi := &polyIn{multiPoly: mpi}
+		vp := &validationPoly{}
+		for j := 0; j < len(geom[i]); j++ {
+			vr := v.newValidationRing(geom[i][j], i, j)
+			if vr == nil {
+				continue
+			}
+			// without a shell there's nothing to check the holes against
+			if j > 0 && vp.shell == nil {
+				continue
+			}
+			vr.poly = vp
+			vr.in = &ringIn{poly: pi, isExterior: vr.isExterior}
+			for k := 0; k < len(vr.points); k++ {
+				edge := &ringIn{poly: pi, isExterior: vr.isExterior}
+				seg, err := v.op.newSegmentFromRing(vr.points[k], vr.points[(k+1)%len(vr.points)], edge)
+				if err != nil {
+					continue
+				}
+				vr.in.segments = append(vr.in.segments, seg)
+				v.edges[edge] = validationEdge{ring: vr, index: k}
+			}
+			if j == 0 {
+				vp.shell = vr
+				pi.exteriorRing = vr.in
+			} else {
+				vp.holes = append(vp.holes, vr)
+				pi.interiorRings = append(pi.interiorRings, vr.in)
+			}
+		}
+		if vp.shell == nil {
+			continue
+		}
+		v.polys = append(v.polys, vp)
+		mpi.polys = append(mpi.polys, pi)
+	}
+
+	// ring intersections and nesting can only be checked after a sweep
+	sl, err := v.op.sweep([]*multiPolyIn{mpi})
+	if err != nil {
+		return nil, err
+	}
+	v.checkIntersections(sl)
+	v.checkNesting(sl)
+
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		if v.issues[i].Polygon != v.issues[j].Polygon {
+			return v.issues[i].Polygon < v.issues[j].Polygon
+		}
+		return v.issues[i].Ring < v.issues[j].Ring
+	})
+	return v.issues, nil
+}
+
+// newValidationRing runs the checks that only need the ring itself. It
+// returns nil if the ring is too broken to take part in the sweep.
+func (v *validator) newValidationRing(ring [][]float64, polygon, index int) *validationRing {
+
+	if len(ring) == 0 {
+		v.report(TooFewPoints, polygon, index, 0, 0)
+		return nil
+	}
+	for i := 0; i < len(ring); i++ {
+		if len(ring[i]) < 2 {
+			x, y := 0.0, 0.0
+			if i > 0 {
+				x, y = ring[i-1][0], ring[i-1][1]
+			}
+			v.report(InvalidCoordinates, polygon, index, x, y)
+			return nil
+		}
+	}
+
+	vr := &validationRing{polygon: polygon, ring: index, isExterior: index == 0}
+
+	for i := 0; i < len(ring); i++ {
+		pt := v.op.rounder.roundFloat(ring[i][0], ring[i][1])
+		// skip repeated points
+		if len(vr.points) > 0 && pt.equal(*vr.points[len(vr.points)-1]) {
+			continue
+		}
+		vr.points = append(vr.points, pt)
+	}
+	if len(vr.points) > 1 && vr.points[0].equal(*vr.points[len(vr.points)-1]) {
+		vr.points = vr.points[:len(vr.points)-1]
+	}
+
+	first := ring[0]
+	last := ring[len(ring)-1]
+	if first[0] != last[0] || first[1] != last[1] {
+		v.report(RingNotClosed, polygon, index, last[0], last[1])
+	}
+
+	if len(vr.points) < 3 {
+		v.report(TooFewPoints, polygon, index, first[0], first[1])
+		return nil
+	}
+
+	// exterior rings counter-clockwise, interior rings clockwise
+	area2 := bigZero()
+	for i := 0; i < len(vr.points); i++ {
+		area2 = area2.plus(crossProduct(vr.points[i].Vector, vr.points[(i+1)%len(vr.points)].Vector))
+	}
+	if (vr.isExterior && area2.isLessThan(bigZero())) || (!vr.isExterior && area2.isGreaterThan(bigZero())) {
+		v.report(WrongOrientation, polygon, index, first[0], first[1])
+	}
+
+	return vr
+}
+
+// checkIntersections looks at every point where the sweep left segments
+// meeting and classifies how the rings pass through it.
+func (v *validator) checkIntersections(sl *sweepLine) {
+
+	type node struct {
+		pt    *point
+		edges []validationEdge
+	}
+	nodes := make(map[string]*node)
+	keys := []string{}
+
+	addEdge := func(pt *point, edge validationEdge) {
+		key := pt.String()
+		n, ok := nodes[key]
+		if !ok {
+			n = &node{pt: pt}
+			nodes[key] = n
+			keys = append(keys, key)
+		}
+		for i := 0; i < len(n.edges); i++ {
+			if n.edges[i] == edge {
+				return
+			}
+		}
+		n.edges = append(n.edges, edge)
+	}
+
+	for i := 0; i < len(sl.segments); i++ {
+		seg := sl.segments[i]
+		if seg.consumedBy != nil {
+			continue
+		}
+		// overlapping edges were consumed into a single segment
+		if len(seg.rings) > 1 {
+			for j := 0; j < len(seg.rings); j++ {
+				v.reportAt(SelfIntersection, v.edges[seg.rings[j]].ring, seg.leftSE.point)
+			}
+		}
+		for j := 0; j < len(seg.rings); j++ {
+			edge := v.edges[seg.rings[j]]
+			addEdge(seg.leftSE.point, edge)
+			addEdge(seg.rightSE.point, edge)
+		}
+	}
+
+	for _, key := range keys {
+		n := nodes[key]
+		passages := v.getPassages(n.pt, n.edges)
+		for i := 0; i < len(passages); i++ {
+			for j := i + 1; j < len(passages); j++ {
+				a, b := passages[i], passages[j]
+				crosses, ok := passagesCross(n.pt, a, b)
+				if !ok {
+					continue
+				}
+				if crosses {
+					v.reportAt(SelfIntersection, a.ring, n.pt)
+					if b.ring != a.ring {
+						v.reportAt(SelfIntersection, b.ring, n.pt)
+					}
+				} else if a.ring == b.ring {
+					v.reportAt(RingSelfIntersection, a.ring, n.pt)
+				}
+			}
+		}
+	}
+}
+
+// getPassages turns the input edges meeting at a point into passages.
+func (v *validator) getPassages(pt *point, edges []validationEdge) []passage {
+	passages := []passage{}
+	vertices := make(map[validationEdge]bool)
+	for i := 0; i < len(edges); i++ {
+		vr := edges[i].ring
+		n := len(vr.points)
+		for _, k := range []int{edges[i].index, (edges[i].index + 1) % n} {
+			if !vr.points[k].equal(*pt) {
+				continue
+			}
+			vertex := validationEdge{ring: vr, index: k}
+			if !vertices[vertex] {
+				vertices[vertex] = true
+				passages = append(passages, passage{
+					ring: vr,
+					from: vr.points[(k+n-1)%n],
+					to:   vr.points[(k+1)%n],
+				})
+			}
+		}
+		from := vr.points[edges[i].index]
+		to := vr.points[(edges[i].index+1)%n]
+		if !from.equal(*pt) && !to.equal(*pt) {
+			passages = append(passages, passage{ring: vr, from: from, to: to, straight: true})
+		}
+	}
+	return passages
+}
+
+// passagesCross reports whether two passages through a point cross each
+// other rather than just touch. It is not ok if the passages share a
+// direction, as overlapping edges are reported separately.
+func passagesCross(pt *point, a, b passage) (crosses bool, ok bool) {
+	var sideFrom, sideTo int
+	switch {
+	case a.straight:
+		sideFrom = sideOfLine(a.from.Vector, a.to.Vector, b.from.Vector)
+		sideTo = sideOfLine(a.from.Vector, a.to.Vector, b.to.Vector)
+	case b.straight:
+		sideFrom = sideOfLine(b.from.Vector, b.to.Vector, a.from.Vector)
+		sideTo = sideOfLine(b.from.Vector, b.to.Vector, a.to.Vector)
+	default:
+		sideFrom = sideOfPath(a.from.Vector, pt.Vector, a.to.Vector, b.from.Vector)
+		sideTo = sideOfPath(a.from.Vector, pt.Vector, a.to.Vector, b.to.Vector)
+	}
+	if sideFrom == 0 || sideTo == 0 {
+		return false, false
+	}
+	return sideFrom != sideTo, true
+}
+
+// sideOfLine returns 1 if c is left of the line through a and b, -1 if it's
+// right of it and 0 if it's on it.
+func sideOfLine(a, b, c Vector) int {
+	ab := Vector{x: b.x.minus(a.x), y: b.y.minus(a.y)}
+	ac := Vector{x: c.x.minus(a.x), y: c.y.minus(a.y)}
+	return crossProduct(ab, ac).Cmp(bigZero())
+}
+
+// sideOfPath returns 1 if the direction from pt to c is left of the path
+// from a through pt to b, -1 if it's right of it and 0 if it's along it.
+func sideOfPath(a, pt, b, c Vector) int {
+	in := Vector{x: a.x.minus(pt.x), y: a.y.minus(pt.y)}
+	out := Vector{x: b.x.minus(pt.x), y: b.y.minus(pt.y)}
+	dir := Vector{x: c.x.minus(pt.x), y: c.y.minus(pt.y)}
+
+	alongRay := func(ray Vector) bool {
+		return crossProduct(ray, dir).isZero() && dotProduct(ray, dir).isGreaterThan(bigZero())
+	}
+	if alongRay(in) || alongRay(out) {
+		return 0
+	}
+
+	// the left side is swept counter-clockwise from out to in
+	var isLeft bool
+	turn := crossProduct(out, in).Cmp(bigZero())
+	switch {
+	case turn > 0:
+		isLeft = crossProduct(out, dir).isGreaterThan(bigZero()) &&
+			crossProduct(dir, in).isGreaterThan(bigZero())
+	case turn < 0:
+		isLeft = !(crossProduct(in, dir).isGreaterThanOrEqualTo(bigZero()) &&
+			crossProduct(dir, out).isGreaterThanOrEqualTo(bigZero()))
+	case dotProduct(out, in).isLessThan(bigZero()):
+		isLeft = crossProduct(out, dir).isGreaterThan(bigZero())
+	default:
+		// the path doubles back on itself, so everything is on its left
+		isLeft = true
+	}
+	if isLeft {
+		return 1
+	}
+	return -1
+}
+
+// checkNesting uses the winding state below the left-most segment of each
+// ring to find the rings enclosing it.
+func (v *validator) checkNesting(sl *sweepLine) {
+
+	leftMostSegs := make(map[*validationRing]*segment)
+	for i := 0; i < len(sl.segments); i++ {
+		seg := sl.segments[i]
+		if seg.consumedBy != nil {
+			continue
+		}
+		for j := 0; j < len(seg.rings); j++ {
+			vr := v.edges[seg.rings[j]].ring
+			if _, ok := leftMostSegs[vr]; !ok {
+				leftMostSegs[vr] = seg
+			}
+		}
+	}
+
+	for _, vp := range v.polys {
+		rings := append([]*validationRing{vp.shell}, vp.holes...)
+		for _, vr := range rings {
+			seg, ok := leftMostSegs[vr]
+			if !ok {
+				continue
+			}
+			enclosing := v.getEnclosingRings(seg, vr)
+			pt := seg.leftSE.point
+
+			if !vr.isExterior {
+				if !enclosing[vp.shell] {
+					v.reportAt(HoleOutsideShell, vr, pt)
+				}
+				for _, hole := range vp.holes {
+					if enclosing[hole] {
+						v.reportAt(NestedHoles, vr, pt)
+					}
+				}
+				continue
+			}
+
+			for other := range enclosing {
+				if !other.isExterior || other.polygon == vr.polygon {
+					continue
+				}
+				inHole := false
+				for _, hole := range other.poly.holes {
+					if enclosing[hole] {
+						inHole = true
+						break
+					}
+				}
+				if !inHole {
+					v.reportAt(NestedShells, vr, pt)
+				}
+			}
+		}
+	}
+}
+
+// getEnclosingRings sums the windings of the input edges below a segment by
+// ring, giving the winding number of each ring around the segment.
+func (v *validator) getEnclosingRings(seg *segment, self *validationRing) map[*validationRing]bool {
+	before := seg.beforeState()
+	windings := make(map[*validationRing]int)
+	for i := 0; i < len(before.rings); i++ {
+		windings[v.edges[before.rings[i]].ring] += before.windings[i]
+	}
+	enclosing := make(map[*validationRing]bool)
+	for vr, winding := range windings {
+		if winding != 0 && vr != self {
+			enclosing[vr] = true
+		}
+	}
+	return enclosing
+}
@@ -0,0 +1,159 @@
+package polygol
+
+import (
+	"testing"
+)
+
+func mustValidate(t *testing.T, geom Geom) []ValidationIssue {
+	t.Helper()
+	issues, err := Validate(geom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return issues
+}
+
+func hasIssue(issues []ValidationIssue, t ValidationIssueType, polygon, ring int, x, y float64) bool {
+	for _, issue := range issues {
+		if issue.Type == t && issue.Polygon == polygon && issue.Ring == ring && issue.X == x && issue.Y == y {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateValid(t *testing.T) {
+	// square
+	issues := mustValidate(t, Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}}})
+	expect(t, len(issues) == 0)
+
+	// square with a hole
+	issues = mustValidate(t, Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{1, 1}, {1, 3}, {3, 3}, {3, 1}, {1, 1}},
+	}})
+	expect(t, len(issues) == 0)
+
+	// hole touching the shell at a point
+	issues = mustValidate(t, Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{0, 2}, {2, 3}, {2, 1}, {0, 2}},
+	}})
+	expect(t, len(issues) == 0)
+
+	// polygons touching at a point
+	issues = mustValidate(t, Geom{
+		{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+		{{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}},
+	})
+	expect(t, len(issues) == 0)
+
+	// polygon in the hole of another polygon
+	issues = mustValidate(t, Geom{
+		{{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}}, {{1, 1}, {1, 5}, {5, 5}, {5, 1}, {1, 1}}},
+		{{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}},
+	})
+	expect(t, len(issues) == 0)
+}
+
+func TestValidateSelfIntersection(t *testing.T) {
+	// bow-tie
+	issues := mustValidate(t, Geom{{{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}}})
+	expect(t, hasIssue(issues, SelfIntersection, 0, 0, 1, 1))
+
+	// hole crossing the shell
+	issues = mustValidate(t, Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{3, 1}, {3, 3}, {5, 3}, {5, 1}, {3, 1}},
+	}})
+	expect(t, hasIssue(issues, SelfIntersection, 0, 0, 4, 1))
+	expect(t, hasIssue(issues, SelfIntersection, 0, 1, 4, 3))
+
+	// polygons sharing an edge
+	issues = mustValidate(t, Geom{
+		{{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}},
+		{{{2, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 0}}},
+	})
+	expect(t, hasIssue(issues, SelfIntersection, 0, 0, 2, 0))
+	expect(t, hasIssue(issues, SelfIntersection, 1, 0, 2, 0))
+}
+
+func TestValidateRingSelfIntersection(t *testing.T) {
+	// ring touching itself at a vertex
+	issues := mustValidate(t, Geom{{{{0, 0}, {4, 0}, {2, 2}, {4, 4}, {0, 4}, {2, 2}, {0, 0}}}})
+	expect(t, len(issues) == 1)
+	expect(t, hasIssue(issues, RingSelfIntersection, 0, 0, 2, 2))
+
+	// vertex of a ring touching one of its edges
+	issues = mustValidate(t, Geom{{{{0, 0}, {4, 0}, {4, 4}, {2, 0}, {0, 4}, {0, 0}}}})
+	expect(t, len(issues) == 1)
+	expect(t, hasIssue(issues, RingSelfIntersection, 0, 0, 2, 0))
+}
+
+func TestValidateNesting(t *testing.T) {
+	// hole outside shell
+	issues := mustValidate(t, Geom{{
+		{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}},
+		{{5, 1}, {5, 3}, {7, 3}, {7, 1}, {5, 1}},
+	}})
+	expect(t, len(issues) == 1)
+	expect(t, hasIssue(issues, HoleOutsideShell, 0, 1, 5, 1))
+
+	// nested holes
+	issues = mustValidate(t, Geom{{
+		{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}},
+		{{1, 1}, {1, 5}, {5, 5}, {5, 1}, {1, 1}},
+		{{2, 2}, {2, 4}, {4, 4}, {4, 2}, {2, 2}},
+	}})
+	expect(t, len(issues) == 1)
+	expect(t, hasIssue(issues, NestedHoles, 0, 2, 2, 2))
+
+	// nested shells
+	issues = mustValidate(t, Geom{
+		{{{0, 0}, {6, 0}, {6, 6}, {0, 6}, {0, 0}}},
+		{{{2, 2}, {4, 2}, {4, 4}, {2, 4}, {2, 2}}},
+	})
+	expect(t, len(issues) == 1)
+	expect(t, hasIssue(issues, NestedShells, 1, 0, 2, 2))
+}
+
+func TestValidateRings(t *testing.T) {
+	// wrong orientation of shell & hole
+	issues := mustValidate(t, Geom{{
+		{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}},
+		{{1, 1}, {3, 1}, {3, 3}, {1, 3}, {1, 1}},
+	}})
+	expect(t, len(issues) == 2)
+	expect(t, hasIssue(issues, WrongOrientation, 0, 0, 0, 0))
+	expect(t, hasIssue(issues, WrongOrientation, 0, 1, 1, 1))
+
+	// too few points
+	issues = mustValidate(t, Geom{{{{0, 0}, {1, 0}, {1, 0}, {0, 0}}}})
+	expect(t, len(issues) == 1)
+	expect(t, hasIssue(issues, TooFewPoints, 0, 0, 0, 0))
+
+	issues = mustValidate(t, Geom{{}})
+	expect(t, len(issues) == 1)
+	expect(t, issues[0].Type == TooFewPoints)
+
+	// ring not closed
+	issues = mustValidate(t, Geom{{{{0, 0}, {4, 0}, {4, 4}, {0, 4}}}})
+	expect(t, len(issues) == 1)
+	expect(t, hasIssue(issues, RingNotClosed, 0, 0, 0, 4))
+
+	// invalid coordinates
+	issues = mustValidate(t, Geom{{{{0, 0}, {4, 0}, {4}, {0, 4}, {0, 0}}}})
+	expect(t, len(issues) == 1)
+	expect(t, hasIssue(issues, InvalidCoordinates, 0, 0, 4, 0))
+}
+
+func TestValidateSweepError(t *testing.T) {
+	defer func(size int) { polygolClippingMaxQueueSize = size }(polygolClippingMaxQueueSize)
+	polygolClippingMaxQueueSize = 2
+
+	// a failed sweep is an error rather than no issues
+	issues, err := Validate(Geom{{{{0, 0}, {2, 2}, {2, 0}, {0, 2}, {0, 0}}}})
+	if err == nil {
+		t.Errorf("expected an error, got issues %v", issues)
+	}
+}